@@ -2,6 +2,8 @@ package cfg
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 )
 
@@ -38,6 +40,24 @@ func WithCLIArgs(args []string) Config {
 	}
 }
 
+// WithJSONArgs sets args from a JSON object keyed by param name. Each value is decoded
+// directly into the type of its param.
+func WithJSONArgs(doc []byte) Config {
+	return func(c configurable) error {
+		var args map[string]json.RawMessage
+		if err := json.Unmarshal(doc, &args); err != nil {
+			return fmt.Errorf("failed to decode JSON args: %w", err)
+		}
+
+		for k, v := range args {
+			if err := c.SetArg(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 func WithMethods(methods InjectableMethods) Config {
 	return func(c configurable) error {
 		c.SetMethods(methods)
@@ -0,0 +1,180 @@
+package cfg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/praetorian-inc/tabularium/pkg/model/model"
+)
+
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// Credential is a resolved credential of a given type, held as named values such as
+// "username" and "password". It never prints or logs its values.
+type Credential struct {
+	Type   model.CredentialType
+	Values map[string]string
+}
+
+func NewCredential(credType model.CredentialType, values map[string]string) *Credential {
+	return &Credential{Type: credType, Values: values}
+}
+
+func (c *Credential) Get(key string) string {
+	return c.Values[key]
+}
+
+// Decode copies the credential's values into v, matching keys to v's json tags.
+func (c *Credential) Decode(v any) error {
+	raw, err := json.Marshal(c.Values)
+	if err != nil {
+		return fmt.Errorf("failed to encode credential %q: %w", c.Type, err)
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("failed to decode credential %q into %T: %w", c.Type, v, err)
+	}
+	return nil
+}
+
+func (c *Credential) keys() []string {
+	keys := []string{}
+	for key := range c.Values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (c *Credential) String() string {
+	return fmt.Sprintf("Credential{Type: %s, Keys: %v}", c.Type, c.keys())
+}
+
+func (c *Credential) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("type", string(c.Type)), slog.Any("keys", c.keys()))
+}
+
+// CredentialProvider resolves credentials by type. Providers return ErrCredentialNotFound
+// when they hold no credential of the requested type, so another provider can be tried.
+type CredentialProvider interface {
+	Resolve(ctx context.Context, credType model.CredentialType) (*Credential, error)
+}
+
+// StaticCredentialProvider resolves credentials from a fixed set, e.g. one built from config.
+type StaticCredentialProvider struct {
+	credentials map[model.CredentialType]*Credential
+}
+
+func NewStaticCredentialProvider(credentials ...*Credential) *StaticCredentialProvider {
+	p := &StaticCredentialProvider{credentials: make(map[model.CredentialType]*Credential)}
+	for _, credential := range credentials {
+		p.credentials[credential.Type] = credential
+	}
+	return p
+}
+
+func (p *StaticCredentialProvider) Resolve(_ context.Context, credType model.CredentialType) (*Credential, error) {
+	credential, ok := p.credentials[credType]
+	if !ok {
+		return nil, ErrCredentialNotFound
+	}
+	return credential, nil
+}
+
+// EnvCredentialProvider resolves credentials from environment variables named
+// <prefix><TYPE>_<KEY>. For example, with prefix "JANUS_CRED_", JANUS_CRED_DOCKER_USERNAME
+// provides the "username" value of the "docker" credential. Characters of the type that are
+// not letters or digits become underscores.
+type EnvCredentialProvider struct {
+	prefix string
+}
+
+func NewEnvCredentialProvider(prefix string) *EnvCredentialProvider {
+	return &EnvCredentialProvider{prefix: prefix}
+}
+
+func (p *EnvCredentialProvider) Resolve(_ context.Context, credType model.CredentialType) (*Credential, error) {
+	typePrefix := p.prefix + envName(string(credType)) + "_"
+
+	values := make(map[string]string)
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		key, ok := strings.CutPrefix(name, typePrefix)
+		if !ok || key == "" {
+			continue
+		}
+		values[strings.ToLower(key)] = value
+	}
+
+	if len(values) == 0 {
+		return nil, ErrCredentialNotFound
+	}
+	return NewCredential(credType, values), nil
+}
+
+func envName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// FileCredentialProvider resolves credentials from a JSON file mapping each credential type
+// to an object of string values:
+//
+//	{"docker": {"username": "user", "password": "pass"}}
+//
+// The file is read on every call, so rotated credentials are picked up without a restart.
+type FileCredentialProvider struct {
+	path string
+}
+
+func NewFileCredentialProvider(path string) *FileCredentialProvider {
+	return &FileCredentialProvider{path: path}
+}
+
+func (p *FileCredentialProvider) Resolve(_ context.Context, credType model.CredentialType) (*Credential, error) {
+	raw, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var credentials map[model.CredentialType]map[string]string
+	if err := json.Unmarshal(raw, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials file %s: %w", p.path, err)
+	}
+
+	values, ok := credentials[credType]
+	if !ok {
+		return nil, ErrCredentialNotFound
+	}
+	return NewCredential(credType, values), nil
+}
+
+// ResolveCredential asks each provider in turn for a credential of credType, returning the
+// first one found.
+func ResolveCredential(ctx context.Context, credType model.CredentialType, providers ...CredentialProvider) (*Credential, error) {
+	for _, provider := range providers {
+		credential, err := provider.Resolve(ctx, credType)
+		if errors.Is(err, ErrCredentialNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return credential, nil
+	}
+	return nil, fmt.Errorf("%w: no provider has a credential of type %q", ErrCredentialNotFound, credType)
+}
@@ -0,0 +1,90 @@
+package cfg_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticCredentialProvider(t *testing.T) {
+	provider := cfg.NewStaticCredentialProvider(
+		cfg.NewCredential("docker", map[string]string{"username": "user", "password": "pass"}),
+	)
+
+	credential, err := provider.Resolve(context.Background(), "docker")
+	require.NoError(t, err)
+	assert.Equal(t, "user", credential.Get("username"))
+
+	_, err = provider.Resolve(context.Background(), "aws")
+	assert.ErrorIs(t, err, cfg.ErrCredentialNotFound)
+}
+
+func TestEnvCredentialProvider(t *testing.T) {
+	t.Setenv("JANUS_TEST_CRED_DOCKER_HUB_USERNAME", "user")
+	t.Setenv("JANUS_TEST_CRED_DOCKER_HUB_PASSWORD", "pass")
+
+	provider := cfg.NewEnvCredentialProvider("JANUS_TEST_CRED_")
+
+	credential, err := provider.Resolve(context.Background(), "docker-hub")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "user", "password": "pass"}, credential.Values)
+
+	_, err = provider.Resolve(context.Background(), "aws")
+	assert.ErrorIs(t, err, cfg.ErrCredentialNotFound)
+}
+
+func TestFileCredentialProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"docker": {"username": "user", "password": "pass"}}`), 0600))
+
+	provider := cfg.NewFileCredentialProvider(path)
+
+	credential, err := provider.Resolve(context.Background(), "docker")
+	require.NoError(t, err)
+	assert.Equal(t, "pass", credential.Get("password"))
+
+	_, err = provider.Resolve(context.Background(), "aws")
+	assert.ErrorIs(t, err, cfg.ErrCredentialNotFound)
+
+	_, err = cfg.NewFileCredentialProvider(filepath.Join(t.TempDir(), "missing.json")).Resolve(context.Background(), "docker")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, cfg.ErrCredentialNotFound)
+}
+
+func TestResolveCredential(t *testing.T) {
+	first := cfg.NewStaticCredentialProvider(cfg.NewCredential("aws", map[string]string{"key": "first"}))
+	second := cfg.NewStaticCredentialProvider(
+		cfg.NewCredential("aws", map[string]string{"key": "second"}),
+		cfg.NewCredential("docker", map[string]string{"key": "second"}),
+	)
+
+	credential, err := cfg.ResolveCredential(context.Background(), "aws", first, second)
+	require.NoError(t, err)
+	assert.Equal(t, "first", credential.Get("key"))
+
+	credential, err = cfg.ResolveCredential(context.Background(), "docker", first, second)
+	require.NoError(t, err)
+	assert.Equal(t, "second", credential.Get("key"))
+
+	_, err = cfg.ResolveCredential(context.Background(), "gcp", first, second)
+	assert.ErrorIs(t, err, cfg.ErrCredentialNotFound)
+}
+
+func TestCredential_Redacted(t *testing.T) {
+	credential := cfg.NewCredential("docker", map[string]string{"username": "user", "password": "hunter2"})
+
+	assert.NotContains(t, fmt.Sprintf("%v", credential), "hunter2")
+	assert.NotContains(t, credential.LogValue().String(), "hunter2")
+
+	var decoded struct {
+		Password string `json:"password"`
+	}
+	require.NoError(t, credential.Decode(&decoded))
+	assert.Equal(t, "hunter2", decoded.Password)
+}
@@ -0,0 +1,54 @@
+package cfg_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readLog(t *testing.T, path string) string {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestRotatingFile_PerRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "janus.log")
+
+	for _, run := range []string{"first\n", "second\n", "third\n"} {
+		file, err := cfg.NewRotatingFile(path, 0, 2)
+		require.NoError(t, err)
+		_, err = file.Write([]byte(run))
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+	}
+
+	assert.Equal(t, "third\n", readLog(t, path))
+	assert.Equal(t, "second\n", readLog(t, path+".1"))
+	assert.Equal(t, "first\n", readLog(t, path+".2"))
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestRotatingFile_MaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "janus.log")
+
+	file, err := cfg.NewRotatingFile(path, 10, 1)
+	require.NoError(t, err)
+	defer file.Close()
+
+	for _, line := range []string{"aaaa\n", "bbbb\n", "cccc\n"} {
+		_, err := file.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, "cccc\n", readLog(t, path))
+	assert.Equal(t, "aaaa\nbbbb\n", readLog(t, path+".1"))
+
+	require.NoError(t, file.Close())
+	_, err = file.Write([]byte("closed\n"))
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package cfg
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
@@ -18,8 +19,24 @@ var (
 	defaultLevel  slog.Level = slog.LevelInfo
 	defaultWriter io.Writer  = os.Stdout
 	defaultColor  bool       = false
+	defaultFormat LogFormat  = LogFormatText
 )
 
+// LogFormat selects how log records are written.
+type LogFormat string
+
+const (
+	// LogFormatText writes slog text records, or tint records when coloring is enabled.
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON writes one JSON object per record, with the link path in a "link" field.
+	LogFormatJSON LogFormat = "json"
+)
+
+// LogLevelParam is the reserved param links read their own log level from, overriding the
+// level of their chain, e.g. cfg.WithArg(cfg.LogLevelParam, "debug"). Links don't need to
+// declare it.
+const LogLevelParam = "log-level"
+
 var Levels = map[string]slog.Level{
 	"debug": slog.LevelDebug,
 	"info":  slog.LevelInfo,
@@ -36,6 +53,25 @@ func LevelFromString(level string) (slog.Level, error) {
 	return lvl, nil
 }
 
+// LevelFromArg converts the value of a log level arg, such as one for LogLevelParam, to a level.
+// The value may be a level name, a slog.Level, or a JSON encoded level name.
+func LevelFromArg(value any) (slog.Level, error) {
+	switch v := value.(type) {
+	case slog.Level:
+		return v, nil
+	case string:
+		return LevelFromString(v)
+	case json.RawMessage:
+		var name string
+		if err := json.Unmarshal(v, &name); err != nil {
+			return slog.LevelInfo, fmt.Errorf("invalid log level: %s", v)
+		}
+		return LevelFromString(name)
+	default:
+		return slog.LevelInfo, fmt.Errorf("invalid log level: %v", value)
+	}
+}
+
 func SetDefaultLevel(level slog.Level) {
 	defaultLevel = level
 }
@@ -48,16 +84,21 @@ func SetDefaultColor(color bool) {
 	defaultColor = color
 }
 
+func SetDefaultFormat(format LogFormat) {
+	defaultFormat = format
+}
+
 type Logger struct {
 	*slog.Logger
 	LinkPath string
 	Level    slog.Level
 	Writer   io.Writer
 	Color    bool
+	Format   LogFormat
 }
 
 func NewLogger() *Logger {
-	logger := &Logger{LinkPath: "", Level: defaultLevel, Writer: defaultWriter, Color: defaultColor}
+	logger := &Logger{LinkPath: "", Level: defaultLevel, Writer: defaultWriter, Color: defaultColor, Format: defaultFormat}
 	return logger
 }
 
@@ -81,6 +122,10 @@ func (l *Logger) SetColor(color bool) {
 	l.Color = color
 }
 
+func (l *Logger) SetFormat(format LogFormat) {
+	l.Format = format
+}
+
 func (l *Logger) Debug(message string, args ...any) {
 	if l == nil || l.Logger == nil {
 		slog.Error("logger is nil", "message", message, "args", args)
@@ -128,16 +173,25 @@ func (l *Logger) Raw(level slog.Level, message string) {
 }
 
 func (l *Logger) Initialize() {
-	handler := DefaultHandler(&l.LinkPath, l.Writer, &l.Level, &l.Color, nil)
+	var handler *Handler
+	if l.Format == LogFormatJSON {
+		handler = JSONHandler(&l.LinkPath, l.Writer, &l.Level, nil)
+	} else {
+		handler = DefaultHandler(&l.LinkPath, l.Writer, &l.Level, &l.Color, nil)
+	}
 	l.Logger = slog.New(handler)
 }
 
+// Handler formats records with a slog handler, adds the link path, and writes them under the
+// package's log lock, so that lines from concurrent links don't interleave. Handlers returned
+// by WithAttrs and WithGroup share the lock and buffer, but not their attrs and groups.
 type Handler struct {
 	// configurable options
 	color    *bool
 	level    *slog.Level
 	linkPath *string
 	writer   io.Writer
+	json     bool
 	// internal state
 	defaultHandler slog.Handler
 	intermediate   *bytes.Buffer
@@ -163,30 +217,48 @@ func DefaultHandler(linkPath *string, w io.Writer, level *slog.Level, color *boo
 	}
 }
 
+// JSONHandler writes each record as a JSON object with the link path as its "link" attribute.
+// The link path is read when the handler is created.
+func JSONHandler(linkPath *string, w io.Writer, level *slog.Level, opts *slog.HandlerOptions) *Handler {
+	intermediate := &bytes.Buffer{}
+
+	var defaultHandler slog.Handler = slog.NewJSONHandler(intermediate, opts)
+	if linkPath != nil && *linkPath != "" {
+		defaultHandler = defaultHandler.WithAttrs([]slog.Attr{slog.String("link", *linkPath)})
+	}
+
+	return &Handler{
+		linkPath:       linkPath,
+		level:          level,
+		writer:         w,
+		json:           true,
+		intermediate:   intermediate,
+		defaultHandler: defaultHandler,
+	}
+}
+
 func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
 	return level >= *h.level
 }
 
 func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
-	h.defaultHandler.Handle(ctx, record)
-
-	message := h.intermediate.String()
-	message = h.insertLinkPath(record.Level, message)
-
 	logLock.Lock()
 	defer logLock.Unlock()
 
-	_, err := h.writer.Write([]byte(message))
-	if err != nil {
+	h.intermediate.Reset()
+	if err := h.defaultHandler.Handle(ctx, record); err != nil {
 		return err
 	}
 
-	h.intermediate.Reset()
-	return nil
+	message := h.intermediate.String()
+	message = h.insertLinkPath(record.Level, message)
+
+	_, err := h.writer.Write([]byte(message))
+	return err
 }
 
 func (h *Handler) insertLinkPath(level slog.Level, message string) string {
-	if h.linkPath == nil || *h.linkPath == "" {
+	if h.json || h.linkPath == nil || *h.linkPath == "" {
 		return message
 	}
 
@@ -209,11 +281,13 @@ func (h *Handler) insertLinkPath(level slog.Level, message string) string {
 }
 
 func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	h.defaultHandler = h.defaultHandler.WithAttrs(attrs)
-	return h
+	clone := *h
+	clone.defaultHandler = h.defaultHandler.WithAttrs(attrs)
+	return &clone
 }
 
 func (h *Handler) WithGroup(name string) slog.Handler {
-	h.defaultHandler = h.defaultHandler.WithGroup(name)
-	return h
+	clone := *h
+	clone.defaultHandler = h.defaultHandler.WithGroup(name)
+	return &clone
 }
@@ -3,6 +3,7 @@ package cfg_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"log/slog"
 	"os"
 	"strings"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLogger(t *testing.T) {
@@ -137,3 +139,53 @@ func TestLogger_SetDefaultColor(t *testing.T) {
 
 	assert.Contains(t, w.String(), "\x1b[92mINF\x1b[0m Info Message")
 }
+
+func TestLogger_JSONFormat(t *testing.T) {
+	w := &bytes.Buffer{}
+	logger := cfg.NewLogger()
+	logger.SetLinkPath("chain/MyLink")
+	logger.SetWriter(w)
+	logger.SetFormat(cfg.LogFormatJSON)
+	logger.Initialize()
+
+	logger.WithGroup("request").Info("test", "status", 200)
+
+	record := map[string]any{}
+	require.NoError(t, json.Unmarshal(w.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "test", record["msg"])
+	assert.Equal(t, "chain/MyLink", record["link"], "the link path should be a top-level attribute")
+	assert.Equal(t, map[string]any{"status": float64(200)}, record["request"])
+}
+
+func TestLogger_WithAttrsDoesNotMutate(t *testing.T) {
+	w := &bytes.Buffer{}
+	linkPath := "MyLink"
+	level := slog.LevelInfo
+	color := false
+	handler := cfg.DefaultHandler(&linkPath, w, &level, &color, nil)
+
+	base := slog.New(handler)
+	base.With("request", "abc").WithGroup("group").Info("derived", "key", "value")
+	base.Info("base")
+
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "link=MyLink msg=derived request=abc group.key=value")
+	assert.Contains(t, lines[1], "link=MyLink msg=base")
+	assert.NotContains(t, lines[1], "request=abc")
+	assert.NotContains(t, lines[1], "group")
+}
+
+func TestLevelFromArg(t *testing.T) {
+	for _, value := range []any{"debug", slog.LevelDebug, json.RawMessage(`"debug"`)} {
+		level, err := cfg.LevelFromArg(value)
+		require.NoError(t, err)
+		assert.Equal(t, slog.LevelDebug, level)
+	}
+
+	_, err := cfg.LevelFromArg("verbose")
+	assert.Error(t, err)
+	_, err = cfg.LevelFromArg(3)
+	assert.Error(t, err)
+}
@@ -1,7 +1,9 @@
 package cfg
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"regexp"
 
 	"github.com/praetorian-inc/janus-framework/pkg/util"
@@ -17,6 +19,7 @@ type Param interface {
 	Shortcode() string
 	Flag() string
 	Required() bool
+	Sensitive() bool
 	HasDefault() bool
 	Type() string
 	String() string
@@ -26,6 +29,7 @@ type Param interface {
 	isSettableTo(any) bool
 	isValidForShortcode() error
 	convertFromCLIString(string) (any, error)
+	schemaProperty() (*SchemaProperty, bool)
 }
 
 type ParamImpl[T any] struct {
@@ -33,6 +37,7 @@ type ParamImpl[T any] struct {
 	description string
 	shortcode   string
 	required    bool
+	sensitive   bool
 	value       T
 	hasValue    bool
 	hasBeenSet  bool
@@ -60,6 +65,10 @@ func (p ParamImpl[T]) Required() bool {
 	return p.required
 }
 
+func (p ParamImpl[T]) Sensitive() bool {
+	return p.sensitive
+}
+
 func (p ParamImpl[T]) HasDefault() bool {
 	return p.hasDefault
 }
@@ -89,6 +98,10 @@ func (p ParamImpl[T]) SetValue(value any) (Param, error) {
 		return p.setValueFromString(valueString)
 	}
 
+	if valueJSON, ok := value.(json.RawMessage); ok {
+		return p.setValueFromJSON(valueJSON)
+	}
+
 	return p.setValueDirectly(value)
 }
 
@@ -101,6 +114,15 @@ func (p ParamImpl[T]) setValueFromString(value string) (Param, error) {
 	return p.setValueDirectly(stringArg)
 }
 
+func (p ParamImpl[T]) setValueFromJSON(value json.RawMessage) (Param, error) {
+	var decoded T
+	if err := json.Unmarshal(value, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON value for parameter %q: %w", p.Name(), err)
+	}
+
+	return p.setValueDirectly(decoded)
+}
+
 func (p ParamImpl[T]) setValueDirectly(value any) (Param, error) {
 	casted, ok := value.(T)
 	if !ok {
@@ -187,6 +209,33 @@ func (p ParamImpl[T]) isValidForRegex(value any) error {
 	return nil
 }
 
+func (p ParamImpl[T]) schemaProperty() (*SchemaProperty, bool) {
+	if isInterface[T]() {
+		return nil, false
+	}
+
+	property, ok := schemaTypeOf(reflect.TypeOf(*new(T)))
+	if !ok {
+		return nil, false
+	}
+
+	property.Description = p.description
+	property.Shortcode = p.shortcode
+	property.GoType = p.Type()
+
+	if p.hasDefault {
+		property.Default = p.value
+	}
+
+	if p.regex != nil && property.Type == "string" {
+		property.Pattern = p.regex.String()
+	} else if p.regex != nil && property.Items != nil && property.Items.Type == "string" {
+		property.Items.Pattern = p.regex.String()
+	}
+
+	return property, true
+}
+
 func (p ParamImpl[T]) String() string {
 	str := fmt.Sprintf("%s: %s", p.name, p.description)
 	if p.hasDefault {
@@ -215,6 +264,13 @@ func (p ParamImpl[T]) AsRequired() ParamImpl[T] {
 	return p
 }
 
+// AsSensitive marks the param's value as a secret, redacted wherever args are shown, such as
+// in plans and reports.
+func (p ParamImpl[T]) AsSensitive() ParamImpl[T] {
+	p.sensitive = true
+	return p
+}
+
 func (p ParamImpl[T]) WithDefault(value T) ParamImpl[T] {
 	p.value = value
 	p.hasDefault = true
@@ -236,3 +292,35 @@ func (p ParamImpl[T]) WithRegex(regex *regexp.Regexp) ParamImpl[T] {
 	p.regex = regex
 	return p
 }
+
+// RedactedValue replaces the values of sensitive args wherever args are shown.
+const RedactedValue = "[REDACTED]"
+
+// sensitiveNamePattern matches the names of params whose values are treated as secrets even if
+// they were not marked AsSensitive. Keywords must be whole words of the name, separated by -, _
+// or a change of case, so names like "author" or "keyword" are not redacted.
+var sensitiveNamePattern = regexp.MustCompile(`(?i)(^|[-_])(pass|password|passphrase|secret|token|api[-_]?key|private[-_]?key|credentials?|auth|cookie|session)($|[-_])`)
+
+// IsSensitive reports whether param's value is a secret: it was marked AsSensitive, or its name
+// looks like a secret's.
+func IsSensitive(param Param) bool {
+	return param.Sensitive() || IsSensitiveName(param.Name())
+}
+
+// IsSensitiveName reports whether the name of a param looks like the name of a secret.
+// camelCase names are split into words like dashed ones, so "apiKey" is as sensitive as "api-key".
+func IsSensitiveName(name string) bool {
+	return sensitiveNamePattern.MatchString(splitCamelCase(name))
+}
+
+var (
+	lowerUpperPattern = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	acronymPattern    = regexp.MustCompile(`([A-Z]+)([A-Z][a-z])`)
+)
+
+// splitCamelCase puts a dash between the words of a camelCase name, e.g. "clientSecret" becomes
+// "client-Secret" and "AWSSecretKey" becomes "AWS-Secret-Key".
+func splitCamelCase(name string) string {
+	name = acronymPattern.ReplaceAllString(name, "$1-$2")
+	return lowerUpperPattern.ReplaceAllString(name, "$1-$2")
+}
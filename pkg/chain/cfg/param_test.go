@@ -50,3 +50,41 @@ func TestParam_ConvertFromCLIString(t *testing.T) {
 	_, err = param.convertFromCLIString("invalid value")
 	assert.EqualError(t, err, `error at index 0: strconv.Atoi: parsing "invalid value": invalid syntax`)
 }
+
+func TestIsSensitiveName(t *testing.T) {
+	tests := []struct {
+		name      string
+		sensitive bool
+	}{
+		{name: "password", sensitive: true},
+		{name: "db-pass", sensitive: true},
+		{name: "aws-secret-key", sensitive: true},
+		{name: "api-token", sensitive: true},
+		{name: "apikey", sensitive: true},
+		{name: "api_key", sensitive: true},
+		{name: "private-key", sensitive: true},
+		{name: "credentials", sensitive: true},
+		{name: "auth", sensitive: true},
+		{name: "basic-auth-header", sensitive: true},
+		{name: "Session_Cookie", sensitive: true},
+		{name: "apiKey", sensitive: true},
+		{name: "authToken", sensitive: true},
+		{name: "clientSecret", sensitive: true},
+		{name: "githubToken", sensitive: true},
+		{name: "AWSSecretKey", sensitive: true},
+		{name: "dbPassword", sensitive: true},
+		{name: "keyword", sensitive: false},
+		{name: "key", sensitive: false},
+		{name: "author", sensitive: false},
+		{name: "passes", sensitive: false},
+		{name: "sessions", sensitive: false},
+		{name: "tokenizer", sensitive: false},
+		{name: "authorName", sensitive: false},
+		{name: "keywordList", sensitive: false},
+		{name: "region", sensitive: false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.sensitive, IsSensitiveName(test.name), test.name)
+	}
+}
@@ -1,6 +1,7 @@
 package cfg
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -22,8 +23,20 @@ type Paramable interface {
 type ParamHolder struct {
 	params  map[string]Param
 	pending map[string]*pendingArg
+	sources map[string]ArgSource
 }
 
+// ArgSource records where the value of an arg came from.
+type ArgSource string
+
+const (
+	ArgSourceUnset   ArgSource = "unset"
+	ArgSourceDefault ArgSource = "default"
+	ArgSourceConfig  ArgSource = "config"
+	ArgSourceCLI     ArgSource = "cli"
+	ArgSourceJSON    ArgSource = "json"
+)
+
 type pendingArg struct {
 	Name    string
 	Flag    string
@@ -43,10 +56,21 @@ func (pa *pendingArg) SetFlag(flag string) {
 	pa.Flag = flag
 }
 
+func (pa *pendingArg) Source() ArgSource {
+	if pa.FromCLI {
+		return ArgSourceCLI
+	}
+	if _, ok := pa.Value.(json.RawMessage); ok {
+		return ArgSourceJSON
+	}
+	return ArgSourceConfig
+}
+
 func NewParamHolder() *ParamHolder {
 	ph := &ParamHolder{
 		params:  make(map[string]Param),
 		pending: make(map[string]*pendingArg),
+		sources: make(map[string]ArgSource),
 	}
 	return ph
 }
@@ -102,12 +126,13 @@ func (ph *ParamHolder) SetParam(param Param) error {
 
 	var err error
 
-	pending, ok := ph.getPendingValue(param)
+	pending, ok := ph.getPendingArg(param)
 	if ok {
-		param, err = param.SetValue(pending)
+		param, err = param.SetValue(pending.Value)
 		if err != nil {
 			return err
 		}
+		ph.sources[param.Name()] = pending.Source()
 		ph.deletePendingValue(param)
 	}
 
@@ -115,17 +140,13 @@ func (ph *ParamHolder) SetParam(param Param) error {
 	return nil
 }
 
-func (ph *ParamHolder) getPendingValue(param Param) (any, bool) {
+func (ph *ParamHolder) getPendingArg(param Param) (*pendingArg, bool) {
 	pending, ok := ph.pending[param.Name()]
 	if !ok {
 		pending, ok = ph.pending[param.Flag()]
 	}
 
-	if ok {
-		return pending.Value, true
-	}
-
-	return nil, false
+	return pending, ok
 }
 
 func (ph *ParamHolder) deletePendingValue(param Param) {
@@ -160,6 +181,20 @@ func (ph *ParamHolder) Args() map[string]any {
 	return args
 }
 
+// ReservedArg returns the value of the named arg whether or not a param is declared for it,
+// for reserved params such as LogLevelParam.
+func (ph *ParamHolder) ReservedArg(name string) (any, bool) {
+	if param, ok := ph.getParam(name); ok {
+		return param.Value(), param.HasValue()
+	}
+
+	pending, ok := ph.pending[name]
+	if !ok {
+		return nil, false
+	}
+	return pending.Value, true
+}
+
 func (ph *ParamHolder) SetArg(name string, value any) error {
 	return ph.setArg(newPendingArg(name, value))
 }
@@ -177,9 +212,31 @@ func (ph *ParamHolder) setArg(arg *pendingArg) error {
 	}
 
 	ph.params[param.Name()] = param
+	ph.sources[param.Name()] = arg.Source()
 	return nil
 }
 
+// ArgSource reports where the current value of the named param came from.
+func (ph *ParamHolder) ArgSource(name string) ArgSource {
+	param, ok := ph.getParam(name)
+	if !ok {
+		return ArgSourceUnset
+	}
+
+	if param.HasBeenSet() {
+		if source, ok := ph.sources[name]; ok {
+			return source
+		}
+		return ArgSourceConfig
+	}
+
+	if param.HasDefault() {
+		return ArgSourceDefault
+	}
+
+	return ArgSourceUnset
+}
+
 func (ph *ParamHolder) getParam(name string) (Param, bool) {
 	param, ok := ph.params[name]
 	return param, ok
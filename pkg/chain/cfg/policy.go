@@ -0,0 +1,236 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// AzureDefaultScope is used as the assignable scope of generated Azure roles when none is given.
+// It must be replaced with the customer's subscription before the role is created.
+const AzureDefaultScope = "/subscriptions/{subscriptionId}"
+
+type AWSPolicyDocument struct {
+	Version   string               `json:"Version"`
+	Statement []AWSPolicyStatement `json:"Statement"`
+}
+
+type AWSPolicyStatement struct {
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource string   `json:"Resource"`
+}
+
+// GCPCustomRole is a custom role definition, in the format accepted by
+// `gcloud iam roles create --file`.
+type GCPCustomRole struct {
+	Title               string   `json:"title"`
+	Description         string   `json:"description,omitempty"`
+	Stage               string   `json:"stage"`
+	IncludedPermissions []string `json:"includedPermissions"`
+}
+
+// AzureCustomRole is a custom role definition, in the format accepted by
+// `az role definition create --role-definition`.
+type AzureCustomRole struct {
+	Name             string   `json:"Name"`
+	IsCustom         bool     `json:"IsCustom"`
+	Description      string   `json:"Description,omitempty"`
+	Actions          []string `json:"Actions"`
+	NotActions       []string `json:"NotActions"`
+	DataActions      []string `json:"DataActions"`
+	NotDataActions   []string `json:"NotDataActions"`
+	AssignableScopes []string `json:"AssignableScopes"`
+}
+
+// GitHubTokenScope is one permission of a fine-grained personal access token, e.g.
+// {Category: "Repository", Permission: "Contents", Access: "read"}.
+type GitHubTokenScope struct {
+	Category   string `json:"category"`
+	Permission string `json:"permission"`
+	Access     string `json:"access"`
+}
+
+var githubAccessLevels = []string{"read", "write", "admin"}
+
+// GeneratePolicies renders a least-privilege policy for each platform in permissions. Policies
+// are deterministic: permissions are deduplicated and sorted, so the same chain always
+// produces the same documents.
+func GeneratePolicies(name, description string, permissions []Permission) (map[Platform][]byte, error) {
+	byPlatform := make(map[Platform][]string)
+	platforms := []Platform{}
+	for _, permission := range permissions {
+		if _, ok := byPlatform[permission.Platform]; !ok {
+			platforms = append(platforms, permission.Platform)
+		}
+		byPlatform[permission.Platform] = append(byPlatform[permission.Platform], permission.Permission)
+	}
+	slices.Sort(platforms)
+
+	policies := make(map[Platform][]byte)
+	errs := []error{}
+	for _, platform := range platforms {
+		policy, err := GeneratePolicy(platform, name, description, byPlatform[platform])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		policies[platform] = policy
+	}
+
+	return policies, errors.Join(errs...)
+}
+
+// GeneratePolicy renders a least-privilege policy for a single platform.
+func GeneratePolicy(platform Platform, name, description string, permissions []string) ([]byte, error) {
+	var policy any
+	var err error
+
+	switch platform {
+	case AWS:
+		policy, err = NewAWSPolicyDocument(permissions)
+	case GCP:
+		policy, err = NewGCPCustomRole(name, description, permissions)
+	case Azure:
+		policy, err = NewAzureCustomRole(name, description, permissions)
+	case GitHub:
+		policy, err = NewGitHubTokenScopes(permissions)
+	default:
+		return nil, fmt.Errorf("no policy generator for platform %q", platform)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s policy: %w", platform, err)
+	}
+
+	return json.MarshalIndent(policy, "", "  ")
+}
+
+// NewAWSPolicyDocument builds an IAM policy allowing the given actions. Actions covered by a
+// service wildcard (e.g. "s3:GetObject" when "s3:*" is present) are dropped.
+func NewAWSPolicyDocument(actions []string) (*AWSPolicyDocument, error) {
+	actions = sortedUnique(actions)
+
+	wildcards := make(map[string]bool)
+	for _, action := range actions {
+		service, name, ok := strings.Cut(action, ":")
+		if !ok || service == "" || name == "" {
+			return nil, fmt.Errorf("invalid AWS action %q: expected service:Action", action)
+		}
+		if name == "*" {
+			wildcards[strings.ToLower(service)] = true
+		}
+	}
+
+	allowed := []string{}
+	for _, action := range actions {
+		service, name, _ := strings.Cut(action, ":")
+		if name != "*" && wildcards[strings.ToLower(service)] {
+			continue
+		}
+		allowed = append(allowed, action)
+	}
+
+	return &AWSPolicyDocument{
+		Version: "2012-10-17",
+		Statement: []AWSPolicyStatement{
+			{Effect: "Allow", Action: allowed, Resource: "*"},
+		},
+	}, nil
+}
+
+func NewGCPCustomRole(title, description string, permissions []string) (*GCPCustomRole, error) {
+	permissions = sortedUnique(permissions)
+	for _, permission := range permissions {
+		if strings.Count(permission, ".") < 2 {
+			return nil, fmt.Errorf("invalid GCP permission %q: expected service.resource.verb", permission)
+		}
+	}
+
+	return &GCPCustomRole{
+		Title:               title,
+		Description:         description,
+		Stage:               "GA",
+		IncludedPermissions: permissions,
+	}, nil
+}
+
+// NewAzureCustomRole builds a custom role granting the given actions. If no scopes are given,
+// the role is assignable at AzureDefaultScope.
+func NewAzureCustomRole(name, description string, actions []string, scopes ...string) (*AzureCustomRole, error) {
+	actions = sortedUnique(actions)
+	for _, action := range actions {
+		if !strings.Contains(action, "/") {
+			return nil, fmt.Errorf("invalid Azure action %q: expected Provider/resource/operation", action)
+		}
+	}
+
+	if len(scopes) == 0 {
+		scopes = []string{AzureDefaultScope}
+	}
+
+	return &AzureCustomRole{
+		Name:             name,
+		IsCustom:         true,
+		Description:      description,
+		Actions:          actions,
+		NotActions:       []string{},
+		DataActions:      []string{},
+		NotDataActions:   []string{},
+		AssignableScopes: sortedUnique(scopes),
+	}, nil
+}
+
+// NewGitHubTokenScopes parses permissions of the form "Category:Permission[:access]", e.g.
+// "Repository:Contents" or "Organization:Members:write". Access defaults to read. When the
+// same permission is requested with several access levels, only the highest is kept.
+func NewGitHubTokenScopes(permissions []string) ([]GitHubTokenScope, error) {
+	scopes := make(map[string]GitHubTokenScope)
+
+	for _, permission := range permissions {
+		parts := strings.Split(permission, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid GitHub permission %q: expected Category:Permission[:access]", permission)
+		}
+
+		scope := GitHubTokenScope{Category: parts[0], Permission: parts[1], Access: "read"}
+		if len(parts) == 3 {
+			scope.Access = strings.ToLower(parts[2])
+		}
+
+		if !slices.Contains(githubAccessLevels, scope.Access) {
+			return nil, fmt.Errorf("invalid GitHub access level %q in %q: expected one of %v", scope.Access, permission, githubAccessLevels)
+		}
+
+		key := scope.Category + ":" + scope.Permission
+		if existing, ok := scopes[key]; ok && githubAccessRank(existing.Access) >= githubAccessRank(scope.Access) {
+			continue
+		}
+		scopes[key] = scope
+	}
+
+	result := make([]GitHubTokenScope, 0, len(scopes))
+	for _, scope := range scopes {
+		result = append(result, scope)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Category != result[j].Category {
+			return result[i].Category < result[j].Category
+		}
+		return result[i].Permission < result[j].Permission
+	})
+	return result, nil
+}
+
+func githubAccessRank(access string) int {
+	return slices.Index(githubAccessLevels, access)
+}
+
+func sortedUnique(values []string) []string {
+	result := append([]string{}, values...)
+	slices.Sort(result)
+	return slices.Compact(result)
+}
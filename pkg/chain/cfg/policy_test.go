@@ -0,0 +1,131 @@
+package cfg_test
+
+import (
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePolicy_AWS(t *testing.T) {
+	policy, err := cfg.GeneratePolicy(cfg.AWS, "scan", "", []string{
+		"s3:GetObject",
+		"ec2:DescribeInstances",
+		"s3:GetObject",
+		"iam:*",
+		"iam:ListRoles",
+	})
+	require.NoError(t, err)
+
+	expected := `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Action": [
+        "ec2:DescribeInstances",
+        "iam:*",
+        "s3:GetObject"
+      ],
+      "Resource": "*"
+    }
+  ]
+}`
+	assert.Equal(t, expected, string(policy))
+}
+
+func TestGeneratePolicy_GCP(t *testing.T) {
+	policy, err := cfg.GeneratePolicy(cfg.GCP, "scan", "scans buckets", []string{
+		"storage.objects.list",
+		"storage.buckets.list",
+		"storage.objects.list",
+	})
+	require.NoError(t, err)
+
+	expected := `{
+  "title": "scan",
+  "description": "scans buckets",
+  "stage": "GA",
+  "includedPermissions": [
+    "storage.buckets.list",
+    "storage.objects.list"
+  ]
+}`
+	assert.Equal(t, expected, string(policy))
+}
+
+func TestGeneratePolicy_Azure(t *testing.T) {
+	role, err := cfg.NewAzureCustomRole("scan", "", []string{
+		"Microsoft.Storage/storageAccounts/read",
+		"Microsoft.Compute/virtualMachines/read",
+		"Microsoft.Storage/storageAccounts/read",
+	})
+	require.NoError(t, err)
+
+	assert.True(t, role.IsCustom)
+	assert.Equal(t, []string{"Microsoft.Compute/virtualMachines/read", "Microsoft.Storage/storageAccounts/read"}, role.Actions)
+	assert.Equal(t, []string{cfg.AzureDefaultScope}, role.AssignableScopes)
+	assert.Empty(t, role.DataActions)
+
+	role, err = cfg.NewAzureCustomRole("scan", "", []string{"Microsoft.Storage/storageAccounts/read"}, "/subscriptions/b", "/subscriptions/a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/subscriptions/a", "/subscriptions/b"}, role.AssignableScopes)
+}
+
+func TestGeneratePolicy_GitHub(t *testing.T) {
+	scopes, err := cfg.NewGitHubTokenScopes([]string{
+		"Repository:Contents",
+		"Organization:Members",
+		"Repository:Contents:write",
+		"Repository:Contents",
+		"Repository:Actions:read",
+	})
+	require.NoError(t, err)
+
+	expected := []cfg.GitHubTokenScope{
+		{Category: "Organization", Permission: "Members", Access: "read"},
+		{Category: "Repository", Permission: "Actions", Access: "read"},
+		{Category: "Repository", Permission: "Contents", Access: "write"},
+	}
+	assert.Equal(t, expected, scopes)
+}
+
+func TestGeneratePolicy_Invalid(t *testing.T) {
+	tests := []struct {
+		platform   cfg.Platform
+		permission string
+	}{
+		{cfg.AWS, "GetObject"},
+		{cfg.GCP, "storage"},
+		{cfg.Azure, "read"},
+		{cfg.GitHub, "Contents"},
+		{cfg.GitHub, "Repository:Contents:owner"},
+		{cfg.Platform("Oracle"), "anything"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.platform)+"/"+tt.permission, func(t *testing.T) {
+			_, err := cfg.GeneratePolicy(tt.platform, "scan", "", []string{tt.permission})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGeneratePolicies(t *testing.T) {
+	permissions := []cfg.Permission{
+		cfg.NewPermission(cfg.AWS, "s3:GetObject"),
+		cfg.NewPermission(cfg.GitHub, "Repository:Contents"),
+		cfg.NewPermission(cfg.AWS, "s3:ListBucket"),
+	}
+
+	first, err := cfg.GeneratePolicies("scan", "", permissions)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.Contains(t, string(first[cfg.AWS]), `"s3:ListBucket"`)
+	assert.Contains(t, string(first[cfg.GitHub]), `"Contents"`)
+
+	second, err := cfg.GeneratePolicies("scan", "", []cfg.Permission{permissions[2], permissions[1], permissions[0]})
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
@@ -0,0 +1,208 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema description of a set of params. Params whose type cannot be
+// represented in JSON (functions, interfaces, channels) are left out of the schema.
+type Schema struct {
+	Schema               string                     `json:"$schema"`
+	Title                string                     `json:"title,omitempty"`
+	Description          string                     `json:"description,omitempty"`
+	Type                 string                     `json:"type"`
+	Properties           map[string]*SchemaProperty `json:"properties"`
+	Required             []string                   `json:"required,omitempty"`
+	AdditionalProperties bool                       `json:"additionalProperties"`
+	InputParam           string                     `json:"x-input-param,omitempty"`
+	Metadata             map[string]any             `json:"x-metadata,omitempty"`
+}
+
+type SchemaProperty struct {
+	Type        string          `json:"type"`
+	Description string          `json:"description,omitempty"`
+	Default     any             `json:"default,omitempty"`
+	Pattern     string          `json:"pattern,omitempty"`
+	Items       *SchemaProperty `json:"items,omitempty"`
+	Shortcode   string          `json:"x-shortcode,omitempty"`
+	GoType      string          `json:"x-go-type,omitempty"`
+}
+
+func NewSchema(params ...Param) *Schema {
+	s := &Schema{
+		Schema:     JSONSchemaDraft,
+		Type:       "object",
+		Properties: make(map[string]*SchemaProperty),
+	}
+
+	for _, param := range params {
+		property, ok := param.schemaProperty()
+		if !ok {
+			continue
+		}
+
+		s.Properties[param.Name()] = property
+		if param.Required() && !param.HasDefault() {
+			s.Required = append(s.Required, param.Name())
+		}
+	}
+
+	sort.Strings(s.Required)
+	return s
+}
+
+// Validate checks a JSON document of args against the schema. All violations are reported.
+func (s *Schema) Validate(doc []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+
+	var args map[string]any
+	if err := decoder.Decode(&args); err != nil {
+		return fmt.Errorf("failed to decode JSON args: %w", err)
+	}
+
+	errs := []error{}
+	for _, name := range s.Required {
+		if _, ok := args[name]; !ok {
+			errs = append(errs, fmt.Errorf("parameter %q is required", name))
+		}
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := s.Properties[name]
+		if !ok {
+			if !s.AdditionalProperties {
+				errs = append(errs, fmt.Errorf("parameter %q is not defined by the schema", name))
+			}
+			continue
+		}
+
+		if err := property.validate(args[name]); err != nil {
+			errs = append(errs, fmt.Errorf("parameter %q: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *SchemaProperty) validate(value any) error {
+	switch p.Type {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %s", jsonKind(value))
+		}
+		return p.validatePattern(str)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected boolean, got %s", jsonKind(value))
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected integer, got %s", jsonKind(value))
+		}
+		if _, err := number.Int64(); err != nil {
+			return fmt.Errorf("expected integer, got %s", number)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return fmt.Errorf("expected number, got %s", jsonKind(value))
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("expected array, got %s", jsonKind(value))
+		}
+		for i, item := range items {
+			if err := p.Items.validate(item); err != nil {
+				return fmt.Errorf("item at index %d: %w", i, err)
+			}
+		}
+	case "object":
+		if _, ok := value.(map[string]any); !ok {
+			return fmt.Errorf("expected object, got %s", jsonKind(value))
+		}
+	}
+	return nil
+}
+
+func (p *SchemaProperty) validatePattern(value string) error {
+	if p.Pattern == "" {
+		return nil
+	}
+
+	regex, err := regexp.Compile(p.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", p.Pattern, err)
+	}
+
+	if !regex.MatchString(value) {
+		return fmt.Errorf("value %q does not match regex %q", value, p.Pattern)
+	}
+	return nil
+}
+
+func jsonKind(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func schemaTypeOf(t reflect.Type) (*SchemaProperty, bool) {
+	switch t.Kind() {
+	case reflect.String:
+		return &SchemaProperty{Type: "string"}, true
+	case reflect.Bool:
+		return &SchemaProperty{Type: "boolean"}, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &SchemaProperty{Type: "integer"}, true
+	case reflect.Float32, reflect.Float64:
+		return &SchemaProperty{Type: "number"}, true
+	case reflect.Slice, reflect.Array:
+		items, ok := schemaTypeOf(t.Elem())
+		if !ok {
+			return nil, false
+		}
+		return &SchemaProperty{Type: "array", Items: items}, true
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, false
+		}
+		return &SchemaProperty{Type: "object"}, true
+	case reflect.Struct:
+		return &SchemaProperty{Type: "object"}, true
+	case reflect.Ptr:
+		return schemaTypeOf(t.Elem())
+	default:
+		return nil, false
+	}
+}
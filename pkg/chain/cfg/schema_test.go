@@ -0,0 +1,97 @@
+package cfg_test
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	schema := cfg.NewSchema(
+		cfg.NewParam[string]("target", "target to scan").AsRequired().WithShortcode("t").WithRegex(regexp.MustCompile(`^[a-z.]+$`)),
+		cfg.NewParam[int]("timeout", "timeout in seconds").WithDefault(30),
+		cfg.NewParam[[]string]("ports", "ports to scan").WithRegex(regexp.MustCompile(`^\d+$`)),
+		cfg.NewParam[bool]("verbose", "verbose output"),
+		cfg.NewParam[io.Writer]("writer", "not representable in JSON"),
+	)
+
+	require.Len(t, schema.Properties, 4)
+	assert.NotContains(t, schema.Properties, "writer")
+	assert.Equal(t, []string{"target"}, schema.Required)
+
+	target := schema.Properties["target"]
+	assert.Equal(t, "string", target.Type)
+	assert.Equal(t, "target to scan", target.Description)
+	assert.Equal(t, "t", target.Shortcode)
+	assert.Equal(t, `^[a-z.]+$`, target.Pattern)
+
+	timeout := schema.Properties["timeout"]
+	assert.Equal(t, "integer", timeout.Type)
+	assert.Equal(t, 30, timeout.Default)
+
+	ports := schema.Properties["ports"]
+	assert.Equal(t, "array", ports.Type)
+	assert.Equal(t, "string", ports.Items.Type)
+	assert.Equal(t, `^\d+$`, ports.Items.Pattern)
+
+	assert.Equal(t, "boolean", schema.Properties["verbose"].Type)
+}
+
+func TestSchema_MarshalJSON(t *testing.T) {
+	schema := cfg.NewSchema(
+		cfg.NewParam[bool]("verbose", "verbose output").WithDefault(false).WithShortcode("v"),
+	)
+
+	encoded, err := json.Marshal(schema)
+	require.NoError(t, err)
+
+	expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"verbose":{"type":"boolean","description":"verbose output","default":false,"x-shortcode":"v","x-go-type":"bool"}},"additionalProperties":false}`
+	assert.JSONEq(t, expected, string(encoded))
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema := cfg.NewSchema(
+		cfg.NewParam[string]("target", "target to scan").AsRequired().WithRegex(regexp.MustCompile(`^[a-z.]+$`)),
+		cfg.NewParam[int]("timeout", "timeout in seconds").WithDefault(30),
+		cfg.NewParam[[]string]("ports", "ports to scan").WithRegex(regexp.MustCompile(`^\d+$`)),
+	)
+
+	assert.NoError(t, schema.Validate([]byte(`{"target": "example.com", "timeout": 10, "ports": ["80", "443"]}`)))
+
+	err := schema.Validate([]byte(`{"timeout": 1.5, "ports": ["80", "http"], "unknown": true}`))
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `parameter "target" is required`)
+	assert.ErrorContains(t, err, `parameter "timeout": expected integer, got 1.5`)
+	assert.ErrorContains(t, err, `parameter "ports": item at index 1: value "http" does not match regex`)
+	assert.ErrorContains(t, err, `parameter "unknown" is not defined by the schema`)
+
+	err = schema.Validate([]byte(`{"target": ["example.com"]}`))
+	assert.ErrorContains(t, err, `parameter "target": expected string, got array`)
+
+	err = schema.Validate([]byte(`not json`))
+	assert.ErrorContains(t, err, "failed to decode JSON args")
+}
+
+func TestWithJSONArgs(t *testing.T) {
+	holder := cfg.NewParamHolder()
+	holder.SetParams(
+		cfg.NewParam[string]("target", "target to scan"),
+		cfg.NewParam[[]int]("ports", "ports to scan"),
+	)
+
+	err := holder.SetArg("target", json.RawMessage(`"example.com"`))
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", holder.Arg("target"))
+
+	err = holder.SetArg("ports", json.RawMessage(`[80, 443]`))
+	require.NoError(t, err)
+	assert.Equal(t, []int{80, 443}, holder.Arg("ports"))
+
+	err = holder.SetArg("ports", json.RawMessage(`"80"`))
+	assert.ErrorContains(t, err, `failed to decode JSON value for parameter "ports"`)
+}
@@ -0,0 +1,40 @@
+package cfg
+
+import (
+	"context"
+	"slices"
+)
+
+// PermissionVerifier checks whether the credentials available for a platform grant a set of
+// permissions, so a chain can fail before it starts instead of partway through a run.
+type PermissionVerifier interface {
+	Platform() Platform
+	// Verify returns the permissions that are not granted. An error means the verifier could
+	// not determine what is granted, not that permissions are missing.
+	Verify(ctx context.Context, permissions []string) ([]string, error)
+}
+
+// StaticPermissionVerifier grants a fixed set of permissions. It stands in for a real
+// verifier in tests and for credentials whose grants are known ahead of time.
+type StaticPermissionVerifier struct {
+	platform Platform
+	granted  []string
+}
+
+func NewStaticPermissionVerifier(platform Platform, granted ...string) *StaticPermissionVerifier {
+	return &StaticPermissionVerifier{platform: platform, granted: granted}
+}
+
+func (v *StaticPermissionVerifier) Platform() Platform {
+	return v.platform
+}
+
+func (v *StaticPermissionVerifier) Verify(_ context.Context, permissions []string) ([]string, error) {
+	missing := []string{}
+	for _, permission := range permissions {
+		if !slices.Contains(v.granted, permission) {
+			missing = append(missing, permission)
+		}
+	}
+	return missing, nil
+}
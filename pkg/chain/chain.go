@@ -1,14 +1,22 @@
 package chain
 
 import (
+	"context"
+	"crypto/ed25519"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"maps"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cherrors"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/metrics"
 	"github.com/praetorian-inc/janus-framework/pkg/util"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Chain is a collection of Links and Outputters that can be used to process data.
@@ -30,6 +38,16 @@ type Chain interface {
 	WithLogLevel(level slog.Level) Chain
 	WithLogWriter(w io.Writer) Chain
 	WithLogColoring(color bool) Chain
+	WithLogFormat(format cfg.LogFormat) Chain
+	WithPermissionVerifiers(verifiers ...cfg.PermissionVerifier) Chain
+	WithCredentialProviders(providers ...cfg.CredentialProvider) Chain
+	WithManifest(path string, signingKey ed25519.PrivateKey) Chain
+	WithEnvelopes() Chain
+	WithTracerProvider(provider trace.TracerProvider) Chain
+	WithMetrics(registry *metrics.Registry) Chain
+	WithProgress(interval time.Duration, reporters ...ProgressReporter) Chain
+	WithExpectedInputs(count int) Chain
+	OnEvent(handler EventHandler) Chain
 	// Waits for the chain to finish processing. Will discard all output if there are no outputters configured.
 	Wait()
 	// Closes the chain. Links will process any remaining data, and then close themselves.
@@ -38,23 +56,44 @@ type Chain interface {
 	Error() error
 	Outputters() []Outputter
 	PermissionsMap() map[cfg.Platform][]string
+	// VerifyPermissions checks the permissions links require against the chain's permission verifiers.
+	VerifyPermissions() error
+	// Plan describes what the chain would do if run, without initializing or running any links.
+	Plan() (*Plan, error)
 	resetParams() error
 }
 
 type BaseChain struct {
-	links        []Link
-	started      bool
-	startLock    sync.Mutex
-	wgOut        sync.WaitGroup
-	outputters   []Outputter
-	chanIn       chan any
-	closeChanIn  sync.Once
-	errLock      sync.Mutex
-	super        Chain // TODO: do I need this?
-	outputItems  []any
-	isClosed     bool
-	addedConfigs []cfg.Config
-	inputParam   cfg.Param
+	links          []Link
+	started        bool
+	startLock      sync.Mutex
+	startOnce      sync.Once
+	wgOut          sync.WaitGroup
+	outputters     []Outputter
+	chanIn         chan any
+	closeChanIn    sync.Once
+	errLock        sync.Mutex
+	super          Chain // TODO: do I need this?
+	outputItems    []any
+	isClosed       bool
+	addedConfigs   []cfg.Config
+	inputParam     cfg.Param
+	verifiers      []cfg.PermissionVerifier
+	credentials    []cfg.CredentialProvider
+	startedAt      time.Time
+	manifestPath   string
+	manifestKey    ed25519.PrivateKey
+	manifestOnce   sync.Once
+	manifestPrior  *fileSnapshot
+	envelopes      bool
+	runID          string
+	runIDOnce      sync.Once
+	tracerProvider trace.TracerProvider
+	traceCtx       context.Context
+	span           trace.Span
+	progress       *progressTracker
+	expectedInputs atomic.Int64
+	ownsEvents     bool
 	*Base
 }
 
@@ -62,6 +101,7 @@ func NewChain(links ...Link) Chain {
 	c := &BaseChain{links: links, chanIn: make(chan any)}
 	c.Base = NewBase(c)
 	c.super = c
+	c.expectedInputs.Store(UnknownInputCount)
 
 	for _, link := range links {
 		if link.isClaimed() {
@@ -147,6 +187,19 @@ func (c *BaseChain) withLogColoring(color bool) Link {
 	return c.super
 }
 
+func (c *BaseChain) WithLogFormat(format cfg.LogFormat) Chain {
+	c.withLogFormat(format)
+	return c.super
+}
+
+func (c *BaseChain) withLogFormat(format cfg.LogFormat) Link {
+	c.Base.withLogFormat(format)
+	for _, link := range c.children() {
+		link.withLogFormat(format)
+	}
+	return c.super
+}
+
 func (c *BaseChain) AddAncestor(name *string) Link {
 	c.Base.AddAncestor(name)
 	for _, link := range c.children() {
@@ -196,7 +249,8 @@ func (c *BaseChain) Send(values ...any) error {
 	}
 
 	for _, v := range values {
-		c.chanIn <- v
+		c.chanIn <- c.envelop(v)
+		c.countSent(1)
 	}
 
 	return nil
@@ -213,17 +267,11 @@ func (c *BaseChain) Close() {
 	c.isClosed = true
 }
 
+// Wait waits for the chain to finish, even if it failed, so that outputters have completed and
+// links have stopped writing before the manifest is written.
 func (c *BaseChain) Wait() {
 	c.startIfUnstarted()
 
-	c.errLock.Lock()
-	err := c.getError()
-	c.errLock.Unlock()
-
-	if err != nil {
-		return
-	}
-
 	if len(c.outputters) == 0 {
 		// caller has called Wait() with no outputters.
 		// c.wgOut.Wait() will deadlock if we do not empty this channel.
@@ -231,16 +279,20 @@ func (c *BaseChain) Wait() {
 	}
 
 	c.wgOut.Wait()
+	c.waitEvents()
+	c.writeManifest()
 }
 
 func (c *BaseChain) Error() error {
+	c.errLock.Lock()
+	defer c.errLock.Unlock()
 	return c.getError()
 }
 
 func (c *BaseChain) startIfUnstarted() {
-	if !c.hasStarted() {
+	c.startOnce.Do(func() {
 		c.start(c.chanIn, c.handleError, c.strictness)
-	}
+	})
 	c.setStarted()
 }
 
@@ -259,25 +311,41 @@ func (c *BaseChain) setStarted() {
 }
 
 func (c *BaseChain) start(prevChan chan any, errHandler func(error), strictness Strictness) {
+	c.startedAt = time.Now()
 	c.initializeLogger()
+	c.startEvents()
 
 	if err := c.resetParams(); err != nil {
 		errHandler(err)
+		c.finishEvents()
+		c.Base.Close() // nothing will be sent, so receivers must not block
 		return
 	}
 
+	if err := c.preflight(); err != nil {
+		errHandler(err)
+		c.finishEvents()
+		c.Base.Close() // nothing will be sent, so receivers must not block
+		return
+	}
+
+	c.snapshotManifest()
 	for _, outputter := range c.outputters {
 		if err := c.startOutputter(outputter); err != nil {
 			errHandler(err)
 		}
 	}
 
+	c.emitChainStarted()
+	c.startSpan()
+	c.startProgress()
+
 	for _, child := range c.children() {
 		prevChan = c.startChild(child, prevChan, errHandler, strictness)
 	}
 
 	c.wgOut.Add(1)
-	go c.collectOutput(prevChan, errHandler)
+	go c.collectOutput(prevChan, errHandler, strictness)
 }
 
 func (c *BaseChain) resetParams() error {
@@ -295,6 +363,8 @@ func (c *BaseChain) startOutputter(outputter Outputter) error {
 	if err != nil {
 		return err
 	}
+	c.snapshotTargets(outputter)
+	c.inheritContext(outputter)
 
 	err = outputter.Initialize()
 	if err != nil {
@@ -307,13 +377,46 @@ func (c *BaseChain) startOutputter(outputter Outputter) error {
 func (c *BaseChain) startChild(child Link, prevChan chan any, errHandler func(error), strictness Strictness) chan any {
 	if err := c.setArgs(child); err != nil {
 		errHandler(err)
-		return nil
+		return skipChild(prevChan)
 	}
+	c.snapshotTargets(child)
 
+	if err := c.injectCredentials(child); err != nil {
+		errHandler(err)
+		return skipChild(prevChan)
+	}
+
+	c.inheritContext(child)
+	c.traceChild(child)
+	c.instrumentChild(child)
+	c.forwardEvents(child)
 	go child.start(prevChan, errHandler, strictness)
 	return child.channel()
 }
 
+// skipChild stands in for a child that failed to start: the items sent to it are discarded,
+// and the links after it get a closed channel, so the chain still winds down.
+func skipChild(prevChan chan any) chan any {
+	if prevChan != nil {
+		go util.EmptyChannel(prevChan)
+	}
+	closed := make(chan any)
+	close(closed)
+	return closed
+}
+
+// inheritContext gives a link or outputter the chain's context, so it is canceled with the
+// chain, unless it was configured with a context of its own.
+func (c *BaseChain) inheritContext(holder any) {
+	h, ok := holder.(interface {
+		Context() context.Context
+		SetContext(context.Context)
+	})
+	if ok && h.Context() == context.Background() {
+		h.SetContext(c.Context())
+	}
+}
+
 func (c *BaseChain) setArgs(paramable cfg.Paramable) error {
 	for key, arg := range c.Args() {
 		expectsParam := paramable.HasParam(key)
@@ -380,48 +483,95 @@ func (c *BaseChain) closeChanInOnce() {
 	})
 }
 
-func (c *BaseChain) collectOutput(lastLinkChan chan any, errHandler func(error)) {
+func (c *BaseChain) collectOutput(lastLinkChan chan any, errHandler func(error), strictness Strictness) {
 	defer func() {
 		c.flushOutputItems()
-		close(c.channel())
 		if err := c.closeOutputters(); err != nil {
 			errHandler(err)
 		}
+		c.endSpan()
+		c.stopProgress()
+		c.finishEvents() // before closing the channel, so the parent chain is still listening
+		close(c.channel())
 		c.wgOut.Done()
 	}()
 
 	for input := range lastLinkChan {
-		if err := c.output(input); err != nil {
+		if err := c.output(input, strictness); err != nil {
 			errHandler(err)
 		}
 	}
 }
 
-func (c *BaseChain) output(value any) error {
+func (c *BaseChain) output(value any, strictness Strictness) error {
+	value, ack := fromTransport(value)
+	defer c.acknowledge(ack)
+
 	if len(c.outputters) == 0 {
 		return c.outputToSelf(value)
 	}
 
-	return c.outputToOutputters(value)
+	return c.outputToOutputters(value, strictness)
 }
 
+// outputToSelf collects value to be received from the chain's channel. Envelopes are kept, so
+// lineage carries on through the links following a nested chain.
 func (c *BaseChain) outputToSelf(value any) error {
-	converted, err := ConvertForLink(value, c)
+	item, envelope := openEnvelope(value)
+	converted, err := ConvertForLink(item, c)
 	if err != nil {
 		return fmt.Errorf("chain collector failed to convert item: %w", err)
 	}
+
+	if envelope != nil {
+		rewrapped := *envelope
+		rewrapped.Value = converted
+		c.outputItems = append(c.outputItems, &rewrapped)
+		return nil
+	}
+
 	c.outputItems = append(c.outputItems, converted)
 	return nil
 }
 
-func (c *BaseChain) outputToOutputters(value any) error {
+// outputToOutputters sends value to each outputter accepting its type. Errors are handled by
+// each outputter's error policy; an error is returned only for those that should kill the chain.
+func (c *BaseChain) outputToOutputters(value any, strictness Strictness) error {
+	item, envelope := openEnvelope(value)
+
+	errs := []error{}
 	for _, outputter := range c.outputters {
-		err := Output(outputter, value)
-		if err != nil {
-			c.Logger.Warn(fmt.Sprintf("chain outputter %T failed to output item", outputter), "item", value, "error", err)
+		if !outputter.accepts(item) {
+			outputter.skip()
+			continue
+		}
+
+		toOutput := item
+		if receiver, ok := outputter.(EnvelopeReceiver); ok && envelope != nil && receiver.ReceivesEnvelopes() {
+			toOutput = envelope
+		}
+
+		err := Output(outputter, toOutput)
+		c.recordOutput(outputter, err)
+		if err == nil {
+			continue
+		}
+
+		if _, isDebugError := err.(*cherrors.DebugError); isDebugError {
+			c.Logger.Debug("encountered debug error in outputter, continuing", "outputter", outputter.Name(), "error", err)
+			continue
+		}
+		c.emitOutputterError(outputter, item, err)
+
+		switch outputter.errorPolicy().resolve(strictness) {
+		case OutputIgnore:
+		case OutputWarn:
+			c.Logger.Warn(fmt.Sprintf("chain outputter %T failed to output item", outputter), "item", item, "error", err)
+		case OutputFail:
+			errs = append(errs, fmt.Errorf("outputter %s encountered error, killing chain due to error policy (%s, strictness %s): %w", outputter.Name(), outputter.errorPolicy(), strictness, err))
 		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 func (c *BaseChain) flushOutputItems() error {
@@ -434,7 +584,11 @@ func (c *BaseChain) flushOutputItems() error {
 
 func (c *BaseChain) closeOutputters() error {
 	for _, outputter := range c.outputters {
+		if skipped := outputter.Skipped(); skipped > 0 {
+			c.Logger.Debug("outputter skipped items of types it does not accept", "outputter", outputter.Name(), "skipped", skipped)
+		}
 		if err := outputter.Complete(); err != nil {
+			c.emitOutputterError(outputter, nil, err)
 			return err
 		}
 	}
@@ -2,11 +2,14 @@ package chain_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
@@ -494,6 +497,122 @@ func TestChain_OutputterReceivesUndeclaredParams(t *testing.T) {
 	assert.Equal(t, "test-profile", receivedParam, "outputter should receive undeclared parameter from chain")
 }
 
+type TestRecordingOutputter struct {
+	*chain.BaseOutputter
+	items []any
+}
+
+func (o *TestRecordingOutputter) Output(val any) error {
+	if val == "fail" {
+		return fmt.Errorf("cannot output %v", val)
+	}
+	o.items = append(o.items, val)
+	return nil
+}
+
+func NewTestRecordingOutputter(configs ...cfg.Config) *TestRecordingOutputter {
+	o := &TestRecordingOutputter{}
+	o.BaseOutputter = chain.NewBaseOutputter(o, configs...)
+	return o
+}
+
+func TestChain_OutputterAcceptedTypes(t *testing.T) {
+	ints := NewTestRecordingOutputter()
+	ints.WithAcceptedTypes(reflect.TypeFor[int]())
+	all := NewTestRecordingOutputter()
+
+	c := chain.NewChain(
+		basics.NewEchoLink(),
+	).WithOutputters(ints, all).WithStrictness(chain.Strict)
+
+	c.Send("one")
+	c.Send(2)
+	c.Send("three")
+	c.Close()
+	c.Wait()
+
+	require.NoError(t, c.Error())
+	assert.Equal(t, []any{2}, ints.items)
+	assert.Equal(t, 2, ints.Skipped())
+	assert.Equal(t, []any{"one", 2, "three"}, all.items)
+	assert.Equal(t, 0, all.Skipped())
+}
+
+func TestChain_OutputterDeclaredTypes(t *testing.T) {
+	mdfile := filepath.Join(t.TempDir(), "out.md")
+	markdown := output.NewMarkdownOutputter()
+
+	c := chain.NewChain(
+		basics.NewStrLink(),
+	).WithConfigs(
+		cfg.WithArg("mdoutfile", mdfile),
+	).WithOutputters(markdown).WithStrictness(chain.Strict)
+
+	c.Send("not markdownable")
+	c.Close()
+	c.Wait()
+
+	require.NoError(t, c.Error())
+	assert.Equal(t, 1, markdown.Skipped())
+}
+
+func TestChain_OutputterErrorPolicy(t *testing.T) {
+	tests := []struct {
+		policy     chain.OutputPolicy
+		strictness chain.Strictness
+		wantErr    bool
+	}{
+		{chain.OutputDefault, chain.Lax, false},
+		{chain.OutputDefault, chain.Moderate, false},
+		{chain.OutputDefault, chain.Strict, true},
+		{chain.OutputIgnore, chain.Strict, false},
+		{chain.OutputWarn, chain.Strict, false},
+		{chain.OutputFail, chain.Lax, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s", tt.policy, tt.strictness), func(t *testing.T) {
+			o := NewTestRecordingOutputter()
+			o.WithErrorPolicy(tt.policy)
+
+			c := chain.NewChain(
+				basics.NewStrLink(),
+			).WithOutputters(o).WithStrictness(tt.strictness).WithLogWriter(io.Discard)
+
+			c.Send("fail")
+			c.Close()
+			c.Wait()
+
+			if tt.wantErr {
+				assert.ErrorContains(t, c.Error(), "cannot output fail")
+			} else {
+				assert.NoError(t, c.Error())
+			}
+		})
+	}
+}
+
+func TestOutputPolicy_String(t *testing.T) {
+	assert.Equal(t, "Fail", chain.OutputFail.String())
+	assert.Equal(t, "OutputPolicy(7)", chain.OutputPolicy(7).String())
+	assert.Equal(t, "Strictness(7)", chain.Strictness(7).String())
+}
+
+func TestChain_OutputterErrorPolicyDefault(t *testing.T) {
+	o := NewTestRecordingOutputter()
+
+	c := chain.NewChain(
+		basics.NewStrLink(),
+	).WithOutputters(o).WithLogWriter(io.Discard)
+
+	c.Send("fail", "two")
+	c.Close()
+	c.Wait()
+
+	assert.NoError(t, c.Error(), "outputter errors should only warn unless the chain is Strict or a policy is set")
+	assert.Equal(t, []any{"two"}, o.items)
+}
+
 func TestChain_Hopper(t *testing.T) {
 	chain1 := chain.NewChain(
 		basics.NewInterfaceLink(),
@@ -876,6 +995,52 @@ func TestChain_Logging_WithLogLevel(t *testing.T) {
 	require.NotContains(t, w.String(), "level=INFO link=test-chain/*basics.LoggingLink msg=\"Info message\"", formatted)
 }
 
+func TestChain_Logging_PerLinkLogLevel(t *testing.T) {
+	w := &bytes.Buffer{}
+	c := chain.NewChain(
+		basics.NewLoggingLink(cfg.WithArg(cfg.LogLevelParam, "debug")),
+		basics.NewLoggingLink(),
+	)
+	c.WithLogWriter(w)
+	c.WithName("test-chain")
+	c.WithLogLevel(slog.LevelWarn)
+
+	c.Send(basics.Msg{Level: slog.LevelDebug, Message: "Debug message"})
+	c.Close()
+	c.Wait()
+
+	require.NoError(t, c.Error())
+	assert.Equal(t, 1, strings.Count(w.String(), "msg=\"Debug message\""), "only the link with a log-level override should log at debug")
+}
+
+func TestChain_Logging_InvalidLogLevel(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewLoggingLink(cfg.WithArg(cfg.LogLevelParam, "verbose")),
+	)
+
+	c.Send(basics.Msg{Level: slog.LevelInfo, Message: "message"})
+	c.Close()
+	c.Wait()
+
+	assert.ErrorContains(t, c.Error(), "invalid log level: verbose")
+}
+
+func TestChain_Logging_JSON(t *testing.T) {
+	w := &bytes.Buffer{}
+	c := chain.NewChain(
+		basics.NewLoggingLink(),
+	).WithName("test-chain").WithLogWriter(w).WithLogFormat(cfg.LogFormatJSON)
+
+	c.Send(basics.Msg{Level: slog.LevelInfo, Message: "test"})
+	c.Close()
+	c.Wait()
+
+	record := map[string]any{}
+	require.NoError(t, json.Unmarshal(w.Bytes(), &record))
+	assert.Equal(t, "test", record["msg"])
+	assert.Equal(t, "test-chain/*basics.LoggingLink", record["link"])
+}
+
 func TestChain_Permissions(t *testing.T) {
 	c := chain.NewChain(
 		basics.NewPermissionsLink().WithPermissions(cfg.NewPermission(cfg.AWS, "permission1")),
@@ -924,3 +1089,122 @@ func TestChain_Permissions_NoPermissions(t *testing.T) {
 	perms := c.Permissions()
 	assert.Empty(t, perms)
 }
+
+func TestChain_PermissionPreflight(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewPermissionsLink().WithPermissions(cfg.NewPermission(cfg.AWS, "s3:GetObject")),
+		chain.NewChain(
+			basics.NewPermissionsLink().WithPermissions(
+				cfg.NewPermission(cfg.AWS, "s3:ListBucket"),
+				cfg.NewPermission(cfg.GCP, "storage.buckets.list"),
+			),
+		),
+	).WithPermissionVerifiers(
+		cfg.NewStaticPermissionVerifier(cfg.AWS, "s3:GetObject"),
+		cfg.NewStaticPermissionVerifier(cfg.GCP, "storage.buckets.list"),
+	)
+
+	err := c.Send("hello")
+	require.Error(t, err)
+	c.Close()
+	c.Wait()
+
+	_, ok := chain.RecvAs[string](c)
+	assert.False(t, ok, "refused chain should produce no output")
+
+	var permErr *chain.PermissionError
+	require.ErrorAs(t, c.Error(), &permErr)
+	require.Len(t, permErr.Missing, 1)
+	assert.Equal(t, cfg.NewPermission(cfg.AWS, "s3:ListBucket"), permErr.Missing[0].Permission)
+	assert.Contains(t, permErr.Missing[0].Link, "PermissionsLink")
+	assert.ErrorContains(t, c.Error(), "requires AWS:s3:ListBucket")
+}
+
+func TestChain_PermissionPreflight_Granted(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewPermissionsLink().WithPermissions(cfg.NewPermission(cfg.AWS, "s3:GetObject")),
+		basics.NewPermissionsLink().WithPermissions(cfg.NewPermission(cfg.Azure, "Microsoft.Storage/storageAccounts/read")),
+	).WithPermissionVerifiers(
+		cfg.NewStaticPermissionVerifier(cfg.AWS, "s3:GetObject"),
+	)
+
+	require.NoError(t, c.VerifyPermissions(), "platforms without a verifier are not checked")
+
+	c.Send("hello")
+	c.Close()
+
+	output, ok := chain.RecvAs[string](c)
+	assert.True(t, ok)
+	assert.Equal(t, "hello", output)
+	assert.NoError(t, c.Error())
+}
+
+func TestChain_PermissionPreflight_Lax(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewPermissionsLink().WithPermissions(cfg.NewPermission(cfg.AWS, "s3:GetObject")),
+	).WithPermissionVerifiers(
+		cfg.NewStaticPermissionVerifier(cfg.AWS),
+	).WithStrictness(chain.Lax)
+
+	c.Send("hello")
+	c.Close()
+
+	output, ok := chain.RecvAs[string](c)
+	assert.True(t, ok, "Lax chain should warn and start anyway")
+	assert.Equal(t, "hello", output)
+	assert.NoError(t, c.Error())
+}
+
+func TestChain_PermissionPreflight_MultiChain(t *testing.T) {
+	c := chain.NewMulti(
+		chain.NewChain(basics.NewPermissionsLink().WithPermissions(cfg.NewPermission(cfg.GitHub, "Repository:Contents"))),
+		chain.NewChain(basics.NewStrLink()),
+	).WithPermissionVerifiers(
+		cfg.NewStaticPermissionVerifier(cfg.GitHub),
+	).WithOutputters(
+		output.NewWriterOutputter(cfg.WithArg("writer", io.Discard)),
+	)
+
+	c.Send("hello")
+	c.Close()
+	c.Wait()
+
+	var permErr *chain.PermissionError
+	assert.ErrorAs(t, c.Error(), &permErr)
+}
+
+func TestChain_CredentialProviders(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewStrLink(),
+		chain.NewChain(basics.NewCredentialLink()),
+	).WithCredentialProviders(
+		cfg.NewStaticCredentialProvider(),
+		cfg.NewStaticCredentialProvider(
+			cfg.NewCredential(basics.MockCredentialType, map[string]string{"username": "user", "password": "pass"}),
+		),
+	)
+
+	c.Send("hello")
+	c.Close()
+
+	output, ok := chain.RecvAs[string](c)
+	assert.True(t, ok)
+	assert.Equal(t, "user", output)
+	assert.NoError(t, c.Error())
+}
+
+func TestChain_CredentialProviders_NotFound(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewCredentialLink(),
+	).WithCredentialProviders(
+		cfg.NewStaticCredentialProvider(),
+	).WithOutputters(
+		output.NewWriterOutputter(cfg.WithArg("writer", io.Discard)),
+	)
+
+	c.Send("hello")
+	c.Close()
+	c.Wait()
+
+	assert.ErrorIs(t, c.Error(), cfg.ErrCredentialNotFound)
+}
@@ -1,5 +1,7 @@
 package chain
 
+import "fmt"
+
 type Strictness int
 
 const (
@@ -9,5 +11,14 @@ const (
 )
 
 func (s Strictness) String() string {
-	return []string{"Moderate", "Lax", "Strict"}[s]
+	switch s {
+	case Moderate:
+		return "Moderate"
+	case Lax:
+		return "Lax"
+	case Strict:
+		return "Strict"
+	default:
+		return fmt.Sprintf("Strictness(%d)", s)
+	}
 }
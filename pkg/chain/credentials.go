@@ -0,0 +1,64 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+)
+
+// WithCredentialProviders makes the chain resolve a credential for every link that declares
+// a CredentialType, before that link is initialized. Providers are tried in order. Nested
+// chains without providers of their own use these.
+func (c *BaseChain) WithCredentialProviders(providers ...cfg.CredentialProvider) Chain {
+	c.credentials = providers
+	return c.super
+}
+
+// OptionalCredentialUser is implemented by links that can do without the credential of their
+// CredentialType, e.g. by falling back to one carried by their inputs. When no provider has
+// one, such links are left without a credential instead of failing.
+type OptionalCredentialUser interface {
+	CredentialOptional() bool
+}
+
+// injectCredentials resolves and sets the credential of link. Links that declare no
+// CredentialType, and chains without credential providers, are left alone.
+func (c *BaseChain) injectCredentials(link Link) error {
+	if nested, ok := asBaseChain(link); ok {
+		if len(nested.credentials) == 0 {
+			nested.credentials = c.credentials
+		}
+		return nil
+	}
+
+	credType := link.CredentialType()
+	if credType == "" || len(c.credentials) == 0 {
+		return nil
+	}
+
+	credential, err := cfg.ResolveCredential(c.Context(), credType, c.credentials...)
+	if optional, ok := link.(OptionalCredentialUser); ok && optional.CredentialOptional() && errors.Is(err, cfg.ErrCredentialNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("link %s failed to resolve credential: %w", link.Name(), err)
+	}
+
+	link.setCredential(credential)
+	return nil
+}
+
+// CredentialAs decodes the credential injected into link into a T, e.g. a struct whose json
+// tags name the credential's values.
+func CredentialAs[T any](link Link) (T, error) {
+	var t T
+
+	credential := link.Credential()
+	if credential == nil {
+		return t, fmt.Errorf("link %s has no credential", link.Name())
+	}
+
+	err := credential.Decode(&t)
+	return t, err
+}
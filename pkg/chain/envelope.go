@@ -0,0 +1,135 @@
+package chain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Envelope carries an item between links together with its lineage. Chains created
+// WithEnvelopes wrap every value passed to Send in an Envelope; links never see envelopes,
+// as their Process methods receive the value, and every value a link sends while processing
+// an enveloped item is wrapped in an envelope inheriting the item's lineage. Values sent
+// outside Process, such as from Complete or a goroutine the link started, inherit the lineage
+// of the last item the link processed; links that batch their inputs can send envelopes
+// themselves to attribute outputs precisely.
+type Envelope struct {
+	Value any `json:"value"`
+	Lineage
+}
+
+// Lineage describes where an item came from.
+type Lineage struct {
+	// RunID identifies the chain run the item belongs to.
+	RunID string `json:"run_id"`
+	// TraceID identifies the input the item descends from. It is derived from that input and
+	// the run ID, so items of one input share it.
+	TraceID string `json:"trace_id"`
+	// Origin is the value passed to Chain.Send that the item descends from.
+	Origin any `json:"origin"`
+	// Links are the paths of the links the item and its ancestors were sent by, oldest first.
+	Links []string `json:"links"`
+}
+
+// EnvelopeReceiver is implemented by outputters that output envelopes rather than their
+// values. Outputters that don't implement it, or return false, receive values as usual.
+type EnvelopeReceiver interface {
+	ReceivesEnvelopes() bool
+}
+
+// WithEnvelopes makes the chain track the lineage of its items: which input each descends
+// from and which links produced it. Outputters implementing EnvelopeReceiver can include the
+// lineage in their output.
+func (c *BaseChain) WithEnvelopes() Chain {
+	c.envelopes = true
+	return c.super
+}
+
+// Envelope returns the envelope of the item the link is processing, or nil if the link's
+// chain doesn't use envelopes.
+func (b *Base) Envelope() *Envelope {
+	return b.current.Load()
+}
+
+// envelop wraps a value passed to Chain.Send, if the chain uses envelopes.
+func (c *BaseChain) envelop(value any) any {
+	if !c.envelopes {
+		return value
+	}
+
+	if t, ok := value.(*transported); ok {
+		return &transported{value: c.envelop(t.value), ack: t.ack}
+	}
+
+	if _, ok := value.(*Envelope); ok {
+		return value // already carries its lineage, e.g. when received from another process
+	}
+
+	c.runIDOnce.Do(func() {
+		c.runID = newRunID()
+	})
+
+	return &Envelope{
+		Value: value,
+		Lineage: Lineage{
+			RunID:   c.runID,
+			TraceID: traceIDFor(c.runID, value),
+			Origin:  value,
+			Links:   []string{},
+		},
+	}
+}
+
+// envelop wraps a value sent by the link in an envelope descending from the item the link is
+// processing, or from the last item it processed when sending outside Process, if that item
+// is enveloped.
+func (b *Base) envelop(value any) any {
+	parent := b.current.Load()
+	if parent == nil {
+		parent = b.last.Load()
+	}
+	if parent == nil {
+		return value
+	}
+
+	if _, ok := value.(*Envelope); ok {
+		return value
+	}
+
+	lineage := parent.Lineage
+	lineage.Links = append(slices.Clip(parent.Links), linkPath(b.super))
+	return &Envelope{Value: value, Lineage: lineage}
+}
+
+// openEnvelope returns the value of v if it is an envelope, along with the envelope.
+func openEnvelope(v any) (any, *Envelope) {
+	if envelope, ok := v.(*Envelope); ok {
+		return envelope.Value, envelope
+	}
+	return v, nil
+}
+
+func newRunID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// traceIDFor derives a 16 byte trace ID from a run ID and an input.
+func traceIDFor(runID string, value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		encoded = []byte(fmt.Sprintf("%#v", value))
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(runID))
+	hash.Write([]byte{0})
+	hash.Write([]byte(fmt.Sprintf("%T", value)))
+	hash.Write([]byte{0})
+	hash.Write(encoded)
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
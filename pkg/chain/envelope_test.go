@@ -0,0 +1,205 @@
+package chain_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// splitLink sends each comma separated part of its input.
+type splitLink struct {
+	*chain.Base
+	mu        sync.Mutex
+	envelopes []*chain.Envelope
+}
+
+func newSplitLink(configs ...cfg.Config) *splitLink {
+	s := &splitLink{}
+	s.Base = chain.NewBase(s, configs...)
+	return s
+}
+
+func (s *splitLink) Process(input string) error {
+	s.mu.Lock()
+	s.envelopes = append(s.envelopes, s.Envelope())
+	s.mu.Unlock()
+
+	for _, part := range strings.Split(input, ",") {
+		s.Send(part)
+	}
+	return nil
+}
+
+// batchLink sends the count of its inputs once they've all been received.
+type batchLink struct {
+	*chain.Base
+	count int
+}
+
+func newBatchLink(configs ...cfg.Config) *batchLink {
+	b := &batchLink{}
+	b.Base = chain.NewBase(b, configs...)
+	return b
+}
+
+func (b *batchLink) Process(input string) error {
+	b.count++
+	return nil
+}
+
+func (b *batchLink) Complete() error {
+	return b.Send(b.count)
+}
+
+type TestEnvelopeOutputter struct {
+	*TestRecordingOutputter
+}
+
+func (o *TestEnvelopeOutputter) ReceivesEnvelopes() bool {
+	return true
+}
+
+func NewTestEnvelopeOutputter() *TestEnvelopeOutputter {
+	o := &TestEnvelopeOutputter{TestRecordingOutputter: &TestRecordingOutputter{}}
+	o.BaseOutputter = chain.NewBaseOutputter(o, nil...)
+	return o
+}
+
+func TestChain_Envelopes(t *testing.T) {
+	split := newSplitLink()
+	envelopes := NewTestEnvelopeOutputter()
+	values := NewTestRecordingOutputter()
+
+	c := chain.NewChain(
+		split,
+		basics.NewStrLink(),
+	).WithOutputters(envelopes, values).WithEnvelopes()
+
+	c.Send("a,b", "c")
+	c.Close()
+	c.Wait()
+	require.NoError(t, c.Error())
+
+	assert.ElementsMatch(t, []any{"a", "b", "c"}, values.items, "outputters not receiving envelopes should receive values")
+
+	require.Len(t, split.envelopes, 2)
+	for _, envelope := range split.envelopes {
+		require.NotNil(t, envelope)
+		assert.Empty(t, envelope.Links)
+	}
+
+	require.Len(t, envelopes.items, 3)
+	traces := map[any]string{}
+	runs := map[string]bool{}
+	for _, item := range envelopes.items {
+		envelope, ok := item.(*chain.Envelope)
+		require.True(t, ok, "expected an envelope, got %T", item)
+
+		require.Len(t, envelope.Links, 2)
+		assert.Contains(t, envelope.Links[0], "splitLink")
+		assert.Contains(t, envelope.Links[1], "StrLink")
+		assert.Len(t, envelope.TraceID, 32)
+		runs[envelope.RunID] = true
+
+		if trace, ok := traces[envelope.Origin]; ok {
+			assert.Equal(t, trace, envelope.TraceID, "items of one input should share a trace ID")
+		}
+		traces[envelope.Origin] = envelope.TraceID
+
+		if envelope.Value == "c" {
+			assert.Equal(t, "c", envelope.Origin)
+		} else {
+			assert.Equal(t, "a,b", envelope.Origin)
+		}
+	}
+
+	assert.Len(t, runs, 1)
+	assert.Len(t, traces, 2)
+	assert.NotEqual(t, traces["a,b"], traces["c"])
+}
+
+func TestChain_EnvelopesNestedChain(t *testing.T) {
+	envelopes := NewTestEnvelopeOutputter()
+
+	c := chain.NewChain(
+		chain.NewChain(
+			basics.NewStrLink(),
+			basics.NewStrIntLink(),
+		),
+		basics.NewIntLink(),
+	).WithOutputters(envelopes).WithEnvelopes()
+
+	c.Send("123")
+	c.Close()
+	c.Wait()
+	require.NoError(t, c.Error())
+
+	require.Len(t, envelopes.items, 1)
+	envelope := envelopes.items[0].(*chain.Envelope)
+	assert.Equal(t, 123, envelope.Value)
+	assert.Equal(t, "123", envelope.Origin)
+	require.Len(t, envelope.Links, 3)
+	assert.Contains(t, envelope.Links[0], "StrLink")
+	assert.Contains(t, envelope.Links[1], "StrIntLink")
+	assert.Contains(t, envelope.Links[2], "IntLink")
+}
+
+func TestChain_EnvelopesRecv(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewStrLink(),
+	).WithEnvelopes()
+
+	c.Send("one", "two")
+	c.Close()
+
+	received := []string{}
+	for output, ok := chain.RecvAs[string](c); ok; output, ok = chain.RecvAs[string](c) {
+		received = append(received, output)
+	}
+
+	require.NoError(t, c.Error())
+	assert.Equal(t, []string{"one", "two"}, received)
+}
+
+func TestChain_WithoutEnvelopes(t *testing.T) {
+	split := newSplitLink()
+	envelopes := NewTestEnvelopeOutputter()
+
+	c := chain.NewChain(split).WithOutputters(envelopes)
+
+	c.Send("a,b")
+	c.Close()
+	c.Wait()
+	require.NoError(t, c.Error())
+
+	assert.Equal(t, []*chain.Envelope{nil}, split.envelopes)
+	assert.Equal(t, []any{"a", "b"}, envelopes.items)
+}
+
+func TestChain_EnvelopesSentFromComplete(t *testing.T) {
+	envelopes := NewTestEnvelopeOutputter()
+
+	c := chain.NewChain(
+		newBatchLink(),
+	).WithOutputters(envelopes).WithEnvelopes()
+
+	c.Send("one", "two")
+	c.Close()
+	c.Wait()
+	require.NoError(t, c.Error())
+
+	require.Len(t, envelopes.items, 1)
+	envelope, ok := envelopes.items[0].(*chain.Envelope)
+	require.True(t, ok, "items sent from Complete should be enveloped")
+	assert.Equal(t, 2, envelope.Value)
+	assert.NotEmpty(t, envelope.RunID)
+	assert.Equal(t, "two", envelope.Origin, "items sent from Complete should descend from the last input")
+	require.Len(t, envelope.Links, 1)
+	assert.Contains(t, envelope.Links[0], "batchLink")
+}
@@ -0,0 +1,286 @@
+package chain
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Event is something that happened while a chain ran. Handlers given to OnEvent switch on its
+// type: ChainStarted, LinkInitialized, ItemProcessed, ItemSent, LinkCompleted, OutputterError
+// or ChainFinished.
+type Event interface {
+	Info() EventInfo
+}
+
+// EventInfo is common to all events.
+type EventInfo struct {
+	Time time.Time
+	// Path is the link path of the chain or link the event is about.
+	Path string
+}
+
+func (e EventInfo) Info() EventInfo {
+	return e
+}
+
+// ChainStarted is sent when a chain starts its links.
+type ChainStarted struct {
+	EventInfo
+}
+
+// LinkInitialized is sent when a link has initialized and is ready to process items.
+type LinkInitialized struct {
+	EventInfo
+}
+
+// ItemProcessed is sent after each call to a link's Process method.
+type ItemProcessed struct {
+	EventInfo
+	Item     any
+	Duration time.Duration
+	Err      error
+}
+
+// ItemSent is sent for each item a link sends to the next link.
+type ItemSent struct {
+	EventInfo
+	Item any
+}
+
+// LinkCompleted is sent when a link has processed all its items and completed, with the error
+// returned by its Complete method, if any.
+type LinkCompleted struct {
+	EventInfo
+	Err error
+}
+
+// OutputterError is sent when one of a chain's outputters fails to output an item or to
+// complete.
+type OutputterError struct {
+	EventInfo
+	Outputter string
+	Item      any
+	Err       error
+}
+
+// ChainFinished is sent when a chain has finished, with the chain's error, if any.
+type ChainFinished struct {
+	EventInfo
+	Duration time.Duration
+	Err      error
+}
+
+// EventHandler receives a chain's events.
+type EventHandler func(Event)
+
+// OnEvent calls handler with the events of the chain, of its links and of the chains nested in
+// it. Events are delivered in the order they happened, one at a time, from a goroutine of the
+// chain's own, so a slow handler never holds up the links. A handler that falls more than
+// maxQueuedEvents behind misses ItemProcessed and ItemSent events until it catches up; the other
+// events are always delivered. Wait returns once handlers have received ChainFinished.
+func (c *BaseChain) OnEvent(handler EventHandler) Chain {
+	if !c.ownsEvents {
+		c.Base.events = newEventBus(c.Base.events)
+		c.ownsEvents = true
+	}
+	c.Base.events.handlers = append(c.Base.events.handlers, handler)
+	return c.super
+}
+
+// maxQueuedEvents bounds the events a bus holds for handlers that have fallen behind. Item
+// events past it are dropped rather than blocking the links that publish them, so a slow handler
+// costs events instead of throughput or memory. Events about chains, links and outputters are
+// few, and are always queued.
+const maxQueuedEvents = 4096
+
+// eventBus queues events for its handlers, and forwards them to the bus of the chain its
+// chain is nested in.
+type eventBus struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	queue    []Event
+	dropped  int
+	handlers []EventHandler
+	parent   *eventBus
+	closed   bool
+	done     chan struct{}
+}
+
+func newEventBus(parent *eventBus) *eventBus {
+	bus := &eventBus{parent: parent}
+	bus.cond = sync.NewCond(&bus.mu)
+	return bus
+}
+
+// publish queues event for the bus's handlers and its parent's. The bus's lock is held while
+// forwarding, so events reach the parent in the same order.
+func (e *eventBus) publish(event Event) {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	switch {
+	case e.closed:
+	case len(e.queue) >= maxQueuedEvents && isItemEvent(event):
+		e.dropped++
+	default:
+		e.queue = append(e.queue, event)
+		e.cond.Signal()
+	}
+	e.parent.publish(event)
+}
+
+func isItemEvent(event Event) bool {
+	switch event.(type) {
+	case ItemProcessed, ItemSent:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *eventBus) start() {
+	e.mu.Lock()
+	e.done = make(chan struct{})
+	e.mu.Unlock()
+	go e.deliver()
+}
+
+func (e *eventBus) deliver() {
+	defer close(e.done)
+	for {
+		e.mu.Lock()
+		for len(e.queue) == 0 && !e.closed {
+			e.cond.Wait()
+		}
+		if len(e.queue) == 0 {
+			dropped := e.dropped
+			e.mu.Unlock()
+			if dropped > 0 {
+				slog.Warn("event handlers fell behind, item events were dropped", "dropped", dropped)
+			}
+			return
+		}
+		event := e.queue[0]
+		e.queue = e.queue[1:]
+		e.mu.Unlock()
+
+		for _, handler := range e.handlers {
+			handler(event)
+		}
+	}
+}
+
+// close stops the bus once its handlers have received every queued event, without waiting for
+// them to.
+func (e *eventBus) close() {
+	e.mu.Lock()
+	e.closed = true
+	e.cond.Signal()
+	e.mu.Unlock()
+}
+
+// wait waits for the bus's handlers to receive every queued event, once it is closed.
+func (e *eventBus) wait() {
+	e.mu.Lock()
+	done := e.done
+	e.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+func (b *Base) setEvents(bus *eventBus) {
+	switch {
+	case b.events == nil:
+		b.events = bus
+	case b.events != bus:
+		b.events.parent = bus
+	}
+}
+
+// forwardEvents makes child publish its events to the chain's bus.
+func (c *BaseChain) forwardEvents(child Link) {
+	if c.events != nil {
+		child.setEvents(c.events)
+	}
+}
+
+func (b *Base) eventInfo() EventInfo {
+	return EventInfo{Time: time.Now(), Path: linkPath(b.super)}
+}
+
+func (c *BaseChain) startEvents() {
+	if c.ownsEvents {
+		c.events.start()
+	}
+}
+
+func (c *BaseChain) emitChainStarted() {
+	if c.events != nil {
+		c.events.publish(ChainStarted{c.eventInfo()})
+	}
+}
+
+// finishEvents sends ChainFinished and closes the chain's bus. It doesn't wait for the chain's
+// handlers, so a slow handler of a nested chain doesn't hold up its parent; see waitEvents.
+func (c *BaseChain) finishEvents() {
+	if c.events == nil {
+		return
+	}
+
+	info := c.eventInfo()
+	c.events.publish(ChainFinished{EventInfo: info, Duration: info.Time.Sub(c.startedAt), Err: c.getError()})
+	if c.ownsEvents {
+		c.events.close()
+	}
+}
+
+// waitEvents waits for the handlers of the chain and of the chains nested in it to receive
+// every event.
+func (c *BaseChain) waitEvents() {
+	if c.ownsEvents {
+		c.events.wait()
+	}
+	for _, link := range c.children() {
+		if nested, ok := asBaseChain(link); ok {
+			nested.waitEvents()
+		}
+	}
+}
+
+func (b *Base) emitLinkInitialized() {
+	if b.events != nil {
+		b.events.publish(LinkInitialized{b.eventInfo()})
+	}
+}
+
+func (b *Base) emitItemProcessed(item any, duration time.Duration, err error) {
+	if b.events != nil {
+		b.events.publish(ItemProcessed{EventInfo: b.eventInfo(), Item: item, Duration: duration, Err: err})
+	}
+}
+
+func (b *Base) emitItemSent(item any) {
+	if b.events != nil {
+		value, _ := openEnvelope(item)
+		b.events.publish(ItemSent{EventInfo: b.eventInfo(), Item: value})
+	}
+}
+
+func (b *Base) emitLinkCompleted(err error) {
+	if b.events != nil {
+		b.events.publish(LinkCompleted{EventInfo: b.eventInfo(), Err: err})
+	}
+}
+
+func (c *BaseChain) emitOutputterError(outputter Outputter, item any, err error) {
+	if c.events != nil {
+		c.events.publish(OutputterError{EventInfo: c.eventInfo(), Outputter: outputter.Name(), Item: item, Err: err})
+	}
+}
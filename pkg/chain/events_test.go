@@ -0,0 +1,184 @@
+package chain_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// describeEvents records each event as "<type> <path>", followed by its item and error.
+func describeEvents(events *[]string) chain.EventHandler {
+	return func(event chain.Event) {
+		desc := strings.TrimPrefix(fmt.Sprintf("%T %s", event, event.Info().Path), "chain.")
+		switch event := event.(type) {
+		case chain.ItemProcessed:
+			desc += fmt.Sprintf(" %v", event.Item)
+			if event.Err != nil {
+				desc += " error"
+			}
+		case chain.ItemSent:
+			desc += fmt.Sprintf(" %v", event.Item)
+		case chain.OutputterError:
+			desc += fmt.Sprintf(" %s %v", event.Outputter, event.Item)
+		}
+		*events = append(*events, desc)
+	}
+}
+
+func TestChain_OnEvent(t *testing.T) {
+	events := []string{}
+	nestedEvents := []string{}
+
+	c := chain.NewChain(
+		basics.NewStrLink(),
+		chain.NewChain(
+			basics.NewEchoLink(),
+		).WithName("nested").OnEvent(describeEvents(&nestedEvents)),
+	).WithName("events").OnEvent(describeEvents(&events))
+
+	c.Send("one", "two")
+	c.Close()
+	c.Wait()
+	require.NoError(t, c.Error())
+
+	assert.Equal(t, "ChainStarted events", events[0])
+	assert.Equal(t, "ChainFinished events", events[len(events)-1], "handlers should have received ChainFinished when Wait returns")
+
+	inOrder := func(events []string, expected ...string) {
+		t.Helper()
+		last := -1
+		for _, event := range expected {
+			i := slices.Index(events, event)
+			require.Greater(t, i, last, "%q is missing or out of order in %v", event, events)
+			last = i
+		}
+	}
+	inOrder(events,
+		"LinkInitialized events/*basics.StrLink",
+		"ItemProcessed events/*basics.StrLink one",
+		"ItemProcessed events/*basics.StrLink two",
+		"LinkCompleted events/*basics.StrLink",
+	)
+	inOrder(events,
+		"ChainStarted events/nested",
+		"ItemSent events/nested/*basics.EchoLink one",
+		"ItemSent events/nested/*basics.EchoLink two",
+		"ChainFinished events/nested",
+		"ChainFinished events",
+	)
+
+	assert.Equal(t, "ChainStarted events/nested", nestedEvents[0])
+	assert.Equal(t, "ChainFinished events/nested", nestedEvents[len(nestedEvents)-1])
+	for _, event := range nestedEvents {
+		assert.Contains(t, event, "events/nested", "nested chain's handler should only see its own events")
+		assert.Contains(t, events, event, "nested chain's events should propagate to its parent")
+	}
+}
+
+func TestMultiChain_OnEvent(t *testing.T) {
+	events := []string{}
+
+	c := chain.NewMulti(
+		chain.NewChain(basics.NewProcessErrorLink()).WithName("failing"),
+		chain.NewChain(basics.NewEchoLink()).WithName("echoing"),
+	).WithName("multi").WithOutputters(NewTestRecordingOutputter()).WithStrictness(chain.Lax).OnEvent(describeEvents(&events))
+
+	c.Send("fail")
+	c.Close()
+	c.Wait()
+
+	assert.Contains(t, events, "ItemProcessed multi/failing/*basics.ProcessErrorLink fail error")
+	assert.Contains(t, events, "ItemProcessed multi/echoing/*basics.EchoLink fail")
+	assert.Contains(t, events, "OutputterError multi *chain_test.TestRecordingOutputter fail")
+	assert.Contains(t, events, "ChainFinished multi/failing")
+	assert.Equal(t, "ChainFinished multi", events[len(events)-1])
+}
+
+func TestChain_OnEventIsAsynchronous(t *testing.T) {
+	release := make(chan struct{})
+	received := 0
+
+	c := chain.NewChain(
+		basics.NewEchoLink(),
+	).OnEvent(func(event chain.Event) {
+		<-release
+		received++
+	})
+
+	// a blocked handler must not hold up the chain
+	c.Send("one", "two", "three")
+	c.Close()
+	close(release)
+	c.Wait()
+
+	assert.Equal(t, 10, received, "1 ChainStarted, 1 LinkInitialized, 3 ItemProcessed, 3 ItemSent, 1 LinkCompleted and 1 ChainFinished")
+}
+
+func TestChain_OnEventDropsItemEventsForSlowHandlers(t *testing.T) {
+	release := make(chan struct{})
+	events := []string{}
+
+	c := chain.NewChain(
+		basics.NewEchoLink(),
+	).WithName("slow").OnEvent(func(event chain.Event) {
+		<-release
+		events = append(events, strings.TrimPrefix(fmt.Sprintf("%T", event), "chain."))
+	})
+
+	items := make([]any, 10000)
+	for i := range items {
+		items[i] = fmt.Sprint(i)
+	}
+
+	// the handler is blocked until every item has been sent, so the queue fills up
+	c.Send(items...)
+	c.Close()
+	close(release)
+	c.Wait()
+
+	assert.Less(t, len(events), 2*len(items), "item events past the queue's bound should be dropped")
+	assert.Contains(t, events, "LinkCompleted", "events other than item events should never be dropped")
+	assert.Equal(t, "ChainFinished", events[len(events)-1])
+}
+
+func TestChain_OnEventNestedHandlerDoesNotHoldUpParent(t *testing.T) {
+	release := make(chan struct{})
+	parentFinished := make(chan struct{})
+	nestedEvents := []string{}
+
+	c := chain.NewChain(
+		chain.NewChain(
+			basics.NewEchoLink(),
+		).WithName("nested").OnEvent(func(event chain.Event) {
+			<-release
+			nestedEvents = append(nestedEvents, strings.TrimPrefix(fmt.Sprintf("%T", event), "chain."))
+		}),
+	).WithName("parent").OnEvent(func(event chain.Event) {
+		if _, ok := event.(chain.ChainFinished); ok && event.Info().Path == "parent" {
+			close(parentFinished)
+		}
+	})
+
+	c.Send("one")
+	c.Close()
+
+	go c.Wait()
+	select {
+	case <-parentFinished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the parent chain should finish while the nested chain's handler is blocked")
+	}
+
+	close(release)
+	c.Wait()
+
+	require.NotEmpty(t, nestedEvents)
+	assert.Equal(t, "ChainFinished", nestedEvents[len(nestedEvents)-1], "Wait should return once nested handlers have received ChainFinished")
+}
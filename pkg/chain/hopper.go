@@ -1,8 +1,11 @@
 package chain
 
+import "sync"
+
 type Hopper struct {
 	*Base
-	chains []Chain
+	chains    []Chain
+	startOnce sync.Once
 }
 
 func NewHopper(chains ...Chain) Link {
@@ -12,7 +15,13 @@ func NewHopper(chains ...Chain) Link {
 }
 
 func (h *Hopper) start(_ chan any, _ func(error), _ Strictness) {
-	go h.processLoop()
+	h.startIfUnstarted()
+}
+
+func (h *Hopper) startIfUnstarted() {
+	h.startOnce.Do(func() {
+		go h.processLoop()
+	})
 }
 
 func (h *Hopper) processLoop() {
@@ -0,0 +1,238 @@
+package chain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// InputSource supplies the inputs for a module run. Inputs are produced lazily, so a source
+// backed by a file or stdin never holds more than one input in memory at a time.
+type InputSource interface {
+	// Inputs yields each input for the chain. link is the first link of the chain, which
+	// sources may use to decode inputs into the type that link processes.
+	Inputs(link Link) iter.Seq2[any, error]
+}
+
+type InputSourceFunc func(link Link) iter.Seq2[any, error]
+
+func (f InputSourceFunc) Inputs(link Link) iter.Seq2[any, error] {
+	return f(link)
+}
+
+// CountableInputSource is implemented by sources that can report how many inputs they will
+// yield without sending any of them.
+type CountableInputSource interface {
+	InputSource
+	Count() (int, error)
+}
+
+type countedInputSource struct {
+	InputSource
+	count func() (int, error)
+}
+
+func (s countedInputSource) Count() (int, error) {
+	return s.count()
+}
+
+const maxInputLineSize = 4 * 1024 * 1024
+
+// AutoRun is the only input sent to a module configured WithAutoRun whose first link processes
+// AutoRun, as the signal to run rather than an item to work on. Other first links are sent the
+// string "autorun" instead.
+type AutoRun struct{}
+
+// autoRunString is the input of auto-run modules whose first link doesn't process AutoRun.
+const autoRunString = "autorun"
+
+// autoRunInputs yields a single AutoRun, or autoRunString if link doesn't process AutoRun.
+func autoRunInputs() InputSource {
+	source := InputSourceFunc(func(link Link) iter.Seq2[any, error] {
+		return func(yield func(any, error) bool) {
+			if processesAutoRun(link) {
+				yield(AutoRun{}, nil)
+			} else {
+				yield(autoRunString, nil)
+			}
+		}
+	})
+	return countedInputSource{InputSource: source, count: func() (int, error) { return 1, nil }}
+}
+
+func processesAutoRun(link Link) bool {
+	method := reflect.ValueOf(link).MethodByName("Process")
+	return method.IsValid() && method.Type().NumIn() == 1 && method.Type().In(0) == reflect.TypeOf(AutoRun{})
+}
+
+// InputsFromSlice yields each item of a slice.
+func InputsFromSlice[T any](items []T) InputSource {
+	source := InputsFromSeq(func(yield func(T) bool) {
+		for _, item := range items {
+			if !yield(item) {
+				return
+			}
+		}
+	})
+	return countedInputSource{InputSource: source, count: func() (int, error) { return len(items), nil }}
+}
+
+// InputsFromSeq yields each value produced by a Go iterator.
+func InputsFromSeq[T any](seq iter.Seq[T]) InputSource {
+	return InputSourceFunc(func(_ Link) iter.Seq2[any, error] {
+		return func(yield func(any, error) bool) {
+			for item := range seq {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// InputsFromReader yields each non-blank line read from r as a string.
+func InputsFromReader(r io.Reader) InputSource {
+	return InputSourceFunc(func(_ Link) iter.Seq2[any, error] {
+		return func(yield func(any, error) bool) {
+			for line, err := range readLines(r) {
+				if !yield(line, err) || err != nil {
+					return
+				}
+			}
+		}
+	})
+}
+
+// InputsFromStdin yields each non-blank line read from stdin as a string.
+func InputsFromStdin() InputSource {
+	return InputsFromReader(os.Stdin)
+}
+
+// InputsFromFile yields each non-blank line of the file at path as a string. The file is
+// opened when iteration begins and closed when it ends.
+func InputsFromFile(path string) InputSource {
+	return countedInputSource{InputSource: inputsFromFile(path, InputsFromReader), count: countLines(path)}
+}
+
+// InputsFromJSONLReader decodes each non-blank line read from r into the input type of the
+// chain's first link, using ConvertForJSON.
+func InputsFromJSONLReader(r io.Reader) InputSource {
+	return InputSourceFunc(func(link Link) iter.Seq2[any, error] {
+		return func(yield func(any, error) bool) {
+			lineNumber := 0
+			for line, err := range readLines(r) {
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				lineNumber++
+
+				decoded, err := ConvertForJSON(line, link)
+				if err != nil {
+					yield(nil, fmt.Errorf("failed to decode JSONL input on line %d: %w", lineNumber, err))
+					return
+				}
+
+				if !yield(reflect.ValueOf(decoded).Elem().Interface(), nil) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// InputsFromJSONL decodes each non-blank line of the file at path into the input type of
+// the chain's first link, using ConvertForJSON.
+func InputsFromJSONL(path string) InputSource {
+	return countedInputSource{InputSource: inputsFromFile(path, InputsFromJSONLReader), count: countLines(path)}
+}
+
+// ParseInputSource converts a CLI value into an InputSource, so it can be used as the
+// converter of a module input param. "-" reads lines from stdin, paths ending in ".jsonl"
+// are decoded as JSONL, and any other value is read as a file of lines.
+func ParseInputSource(value string) (InputSource, error) {
+	if value == "" {
+		return nil, fmt.Errorf("input source is empty")
+	}
+
+	if value == "-" {
+		return InputsFromStdin(), nil
+	}
+
+	if strings.HasSuffix(value, ".jsonl") {
+		return InputsFromJSONL(value), nil
+	}
+
+	return InputsFromFile(value), nil
+}
+
+func readLines(r io.Reader) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxInputLineSize)
+
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			if !yield(line, nil) {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield("", fmt.Errorf("failed to read input: %w", err))
+		}
+	}
+}
+
+func inputsFromFile(path string, fromReader func(io.Reader) InputSource) InputSource {
+	return InputSourceFunc(func(link Link) iter.Seq2[any, error] {
+		return func(yield func(any, error) bool) {
+			file, err := os.Open(path)
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to open input file: %w", err))
+				return
+			}
+			defer file.Close()
+
+			for input, err := range fromReader(file).Inputs(link) {
+				if !yield(input, err) || err != nil {
+					return
+				}
+			}
+		}
+	})
+}
+
+func countLines(path string) func() (int, error) {
+	return func() (int, error) {
+		file, err := os.Open(path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open input file: %w", err)
+		}
+		defer file.Close()
+
+		count := 0
+		for _, err := range readLines(file) {
+			if err != nil {
+				return 0, err
+			}
+			count++
+		}
+		return count, nil
+	}
+}
+
+// firstLink returns the link that receives a chain's input, descending into nested chains.
+func firstLink(link Link) Link {
+	for link.isChain() && len(link.children()) > 0 {
+		link = link.children()[0]
+	}
+	return link
+}
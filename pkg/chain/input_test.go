@@ -0,0 +1,128 @@
+package chain_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/output"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newInputTestModule(w *bytes.Buffer, inputParam cfg.Param) *chain.Module {
+	return chain.NewModule(
+		cfg.NewMetadata(
+			"input-test",
+			"input test",
+		).WithChainInputParam("targets"),
+	).WithLinks(
+		basics.NewStrLink,
+		basics.NewStrIntLink,
+	).WithConfigs(
+		cfg.WithArg("writer", w),
+	).WithInputParam(
+		inputParam,
+	).WithOutputters(
+		output.NewWriterOutputter,
+	)
+}
+
+func TestModule_InputSourceParam(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "targets.txt")
+	require.NoError(t, os.WriteFile(path, []byte("1\n2\n\n  3  \n"), 0644))
+
+	w := &bytes.Buffer{}
+	module := newInputTestModule(w, cfg.NewParam[chain.InputSource]("targets", "file of targets").WithConverter(chain.ParseInputSource))
+
+	err := module.Run(cfg.WithCLIArgs([]string{"-targets", path}))
+	require.NoError(t, err)
+	assert.Equal(t, "1\n2\n3\n", w.String())
+}
+
+func TestModule_RunWithInputs_Seq(t *testing.T) {
+	w := &bytes.Buffer{}
+	module := newInputTestModule(w, cfg.NewParam[[]string]("targets", "targets"))
+
+	err := module.RunWithInputs(chain.InputsFromSeq(slices.Values([]string{"4", "5", "6"})))
+	require.NoError(t, err)
+	assert.Equal(t, "4\n5\n6\n", w.String())
+}
+
+func TestModule_RunWithInputs_Reader(t *testing.T) {
+	w := &bytes.Buffer{}
+	module := newInputTestModule(w, cfg.NewParam[[]string]("targets", "targets"))
+
+	err := module.RunWithInputs(chain.InputsFromReader(strings.NewReader("7\n8\n")))
+	require.NoError(t, err)
+	assert.Equal(t, "7\n8\n", w.String())
+}
+
+func TestModule_RunWithInputs_MissingFile(t *testing.T) {
+	w := &bytes.Buffer{}
+	module := newInputTestModule(w, cfg.NewParam[[]string]("targets", "targets"))
+
+	err := module.RunWithInputs(chain.InputsFromFile(filepath.Join(t.TempDir(), "missing.txt")))
+	assert.ErrorContains(t, err, "failed to open input file")
+	assert.Empty(t, w.String())
+}
+
+func TestModule_RunWithInputs_JSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "domains.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"domain": "example.com"}`+"\n"+`{"domain": "example.org"}`+"\n"), 0644))
+
+	w := &bytes.Buffer{}
+	module := chain.NewModule(
+		cfg.NewMetadata(
+			"jsonl-test",
+			"jsonl test",
+		),
+	).WithLinks(
+		mocks.NewSubdomain,
+	).WithConfigs(
+		cfg.WithArg("writer", w),
+		cfg.WithArg("subdomains", []string{"www"}),
+	).WithOutputters(
+		output.NewWriterOutputter,
+	)
+
+	err := module.RunWithInputs(chain.InputsFromJSONL(path))
+	require.NoError(t, err)
+	assert.Contains(t, w.String(), "www.example.com")
+	assert.Contains(t, w.String(), "www.example.org")
+}
+
+func TestModule_RunWithInputs_InvalidJSONL(t *testing.T) {
+	w := &bytes.Buffer{}
+	module := chain.NewModule(
+		cfg.NewMetadata(
+			"jsonl-test",
+			"jsonl test",
+		),
+	).WithLinks(
+		mocks.NewSubdomain,
+	).WithConfigs(
+		cfg.WithArg("writer", w),
+	).WithOutputters(
+		output.NewWriterOutputter,
+	)
+
+	err := module.RunWithInputs(chain.InputsFromJSONLReader(strings.NewReader(`{"domain": "example.com"}` + "\nnot json\n")))
+	assert.ErrorContains(t, err, "failed to decode JSONL input on line 2")
+}
+
+func TestParseInputSource(t *testing.T) {
+	_, err := chain.ParseInputSource("")
+	assert.Error(t, err)
+
+	source, err := chain.ParseInputSource("-")
+	assert.NoError(t, err)
+	assert.NotNil(t, source)
+}
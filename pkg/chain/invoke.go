@@ -0,0 +1,72 @@
+package chain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/util"
+)
+
+// Invoke runs a chain to completion: it sends inputs, closes the chain, and returns its outputs
+// converted to T. The error joins the chain's error, any failure to send an input, and the
+// outputs that could not be converted to T, which are left out of the outputs.
+//
+// It works with any chain, including MultiChains. Items received by the chain's outputters are
+// not returned. ctx becomes the context of the chain and its links, unless they were configured
+// with their own. If ctx is done first, Invoke returns the outputs received so far along with
+// ctx's error, and the chain winds down in the background.
+func Invoke[T any](ctx context.Context, c Chain, inputs ...any) ([]T, error) {
+	if bc, ok := asBaseChain(c); ok {
+		bc.Base.WithConfigs(cfg.WithContext(ctx))
+	}
+
+	sent := make(chan error, 1)
+	go func() {
+		defer c.Close()
+		for _, input := range inputs {
+			if ctx.Err() != nil {
+				break
+			}
+			if err := c.Send(input); err != nil {
+				sent <- err
+				return
+			}
+		}
+		sent <- nil
+	}()
+
+	outputs := []T{}
+	errs := []error{}
+	out := c.channel()
+	for received := false; !received; {
+		select {
+		case <-ctx.Done():
+			go util.EmptyChannel(out)
+			return outputs, errors.Join(append(errs, ctx.Err())...)
+		case v, ok := <-out:
+			if !ok {
+				received = true
+				continue
+			}
+			output, err := convertOutput[T](v)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			outputs = append(outputs, output)
+		}
+	}
+
+	sendErr := <-sent
+	c.Wait()
+
+	chainErr := c.Error()
+	if sendErr != nil && !errors.Is(sendErr, chainErr) {
+		errs = append([]error{sendErr}, errs...)
+	}
+	if chainErr != nil {
+		errs = append([]error{chainErr}, errs...)
+	}
+	return outputs, errors.Join(errs...)
+}
@@ -0,0 +1,104 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cherrors"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoke(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewStrLink(),
+		basics.NewStrIntLink(),
+	)
+
+	outputs, err := chain.Invoke[int](context.Background(), c, "1", "2", "3")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, outputs)
+}
+
+func TestInvoke_MultiChain(t *testing.T) {
+	add := func(i int) int { return i + 1 }
+	sub := func(i int) int { return i - 1 }
+
+	m := chain.NewMulti(
+		chain.NewChain(basics.NewIntLink(cfg.WithArg("intOp", add))),
+		chain.NewChain(basics.NewIntLink(cfg.WithArg("intOp", sub))),
+	)
+
+	outputs, err := chain.Invoke[int](context.Background(), m, 10, 20)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{9, 11, 19, 21}, outputs)
+}
+
+func TestInvoke_NoInputs(t *testing.T) {
+	outputs, err := chain.Invoke[string](context.Background(), chain.NewChain(basics.NewEchoLink()))
+	require.NoError(t, err)
+	assert.Empty(t, outputs)
+}
+
+func TestInvoke_ConversionErrors(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewEchoLink(),
+	)
+
+	outputs, err := chain.Invoke[int](context.Background(), c, 1, "two", 3)
+	assert.Equal(t, []int{1, 3}, outputs, "items that convert should still be returned")
+
+	var conversionErr *cherrors.ConversionError
+	assert.ErrorAs(t, err, &conversionErr)
+}
+
+func TestInvoke_ChainError(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewErrorLink(cfg.WithArg("errorAt", "process")),
+	).WithStrictness(chain.Strict)
+
+	_, err := chain.Invoke[string](context.Background(), c, "one", "two", "three")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "process error")
+	assert.ErrorIs(t, err, c.Error())
+}
+
+type blockingLink struct {
+	*chain.Base
+	canceled chan struct{}
+}
+
+func newBlockingLink() *blockingLink {
+	l := &blockingLink{canceled: make(chan struct{})}
+	l.Base = chain.NewBase(l)
+	return l
+}
+
+func (l *blockingLink) Process(input string) error {
+	<-l.Context().Done()
+	close(l.canceled)
+	return l.Context().Err()
+}
+
+func TestInvoke_ContextCanceled(t *testing.T) {
+	link := newBlockingLink()
+	c := chain.NewChain(link)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err := chain.Invoke[string](ctx, c, "one")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(started), 500*time.Millisecond, "Invoke should return once ctx is done")
+
+	select {
+	case <-link.canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the link's context should have been canceled with ctx")
+	}
+}
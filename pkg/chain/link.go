@@ -1,15 +1,20 @@
 package chain
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/praetorian-inc/tabularium/pkg/model/model"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
 	"github.com/praetorian-inc/janus-framework/pkg/chain/cherrors"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/metrics"
 )
 
 type Link interface {
@@ -18,11 +23,13 @@ type Link interface {
 	withLogWriter(io.Writer) Link
 	withLogLevel(slog.Level) Link
 	withLogColoring(bool) Link
+	withLogFormat(cfg.LogFormat) Link
 	AddAncestor(*string) Link
 	Name() string
 	SetName(string)
 	Title() string
 	SetTitle(string)
+	SetTransport(Transport)
 	Initialize() error
 	Send(...any) error
 	Close()
@@ -36,6 +43,12 @@ type Link interface {
 	Error() error
 	SetError(error)
 	CredentialType() model.CredentialType
+	// Credential returns the credential resolved for the link's CredentialType, if any.
+	Credential() *cfg.Credential
+	setCredential(*cfg.Credential)
+	setTraceParent(context.Context)
+	setMetrics(*metrics.Registry)
+	setEvents(*eventBus)
 	claim()
 	// start is the main entry point for the link. It must be called from a goroutine.
 	start(chan any, func(error), Strictness)
@@ -56,6 +69,17 @@ type Base struct {
 	err         error
 	claimed     bool
 	permissions []cfg.Permission
+	credential  *cfg.Credential
+	current     atomic.Pointer[Envelope] // envelope of the item being processed
+	last        atomic.Pointer[Envelope] // envelope of the last enveloped item processed
+	traceParent context.Context          // context carrying the span of the link's chain, if tracing
+	processSpan atomic.Pointer[trace.Span]
+	metrics     *metrics.Registry
+	completed   atomic.Int64 // items processed, for progress reporting
+	failed      atomic.Int64
+	events      *eventBus
+	transport   Transport
+	transported bool // the link is running with its transport
 }
 
 func NewBase(link Link, configs ...cfg.Config) *Base {
@@ -136,6 +160,17 @@ func (b *Base) CredentialType() model.CredentialType {
 	return ""
 }
 
+func (b *Base) Credential() *cfg.Credential {
+	if b == nil {
+		return nil
+	}
+	return b.credential
+}
+
+func (b *Base) setCredential(credential *cfg.Credential) {
+	b.credential = credential
+}
+
 func (b *Base) Params() []cfg.Param {
 	if b == nil {
 		return nil
@@ -171,7 +206,14 @@ func (b *Base) Permissions() []cfg.Permission {
 
 func (b *Base) Send(values ...any) error {
 	for _, v := range values {
-		b.ch <- v
+		if b.transported {
+			if err := b.sendToTransport(b.envelop(v)); err != nil {
+				return err
+			}
+		} else {
+			b.ch <- b.envelop(v)
+		}
+		b.emitItemSent(v)
 	}
 	return nil
 }
@@ -190,12 +232,19 @@ func (b *Base) start(prevChannel chan any, errHandler func(error), strictness St
 	}()
 
 	b.initializeLogger()
+	b.startTransport(errHandler)
+
+	if err := b.applyLogLevel(); err != nil {
+		errHandler(err)
+		return
+	}
 
 	err := b.initialize(errHandler)
 	if err != nil {
 		errHandler(err)
 		return
 	}
+	b.emitLinkInitialized()
 
 	pc := prevChannel
 	b.processLoop(pc, errHandler, strictness)
@@ -219,6 +268,7 @@ func (b *Base) initialize(errHandler func(error)) error {
 
 func (b *Base) cleanup(errHandler func(error)) error {
 	err := b.super.Complete()
+	b.emitLinkCompleted(err)
 	if err != nil {
 		err = fmt.Errorf("failed to complete link: %w", err)
 		errHandler(err)
@@ -243,7 +293,24 @@ func (b *Base) processLoop(prevChannel chan any, errHandler func(error), strictn
 }
 
 func (b *Base) process(v any, errHandler func(error)) error {
-	err := Process(b.super, v)
+	v, ack := fromTransport(v)
+	defer b.acknowledge(ack)
+
+	value, envelope := openEnvelope(v)
+	b.current.Store(envelope)
+	if envelope != nil {
+		b.last.Store(envelope)
+	}
+	span := b.startProcessSpan(value)
+	started := time.Now()
+	err := Process(b.super, value)
+	duration := time.Since(started)
+	b.recordProcess(duration, err)
+	b.countItem(err)
+	b.emitItemProcessed(value, duration, err)
+	b.endProcessSpan(span, err)
+	b.current.Store(nil)
+
 	if b.shouldBreak(err) {
 		err = fmt.Errorf("link encountered error, killing chain due to strictness (%s): %w", b.strictness.String(), err)
 		errHandler(err)
@@ -294,7 +361,14 @@ func (b *Base) logLinkError(err error, isConversionError bool) {
 
 func (b *Base) Close() {
 	b.closeOnce.Do(func() {
-		close(b.ch)
+		if !b.transported {
+			close(b.ch)
+			return
+		}
+		// the channel is closed once the items left in the transport have been received
+		if err := b.transport.Close(); err != nil {
+			b.Logger.Error("failed to close transport", "error", err)
+		}
 	})
 }
 
@@ -383,6 +457,26 @@ func (b *Base) withLogColoring(color bool) Link {
 	return b.super
 }
 
+func (b *Base) withLogFormat(format cfg.LogFormat) Link {
+	b.Logger.SetFormat(format)
+	return b.super
+}
+
+// applyLogLevel sets the link's log level from the reserved log-level param, if it was set.
+func (b *Base) applyLogLevel() error {
+	value, ok := b.ReservedArg(cfg.LogLevelParam)
+	if !ok {
+		return nil
+	}
+
+	level, err := cfg.LevelFromArg(value)
+	if err != nil {
+		return fmt.Errorf("link %s: %w", b.Name(), err)
+	}
+	b.Logger.SetLevel(level)
+	return nil
+}
+
 func (b *Base) AddAncestor(name *string) Link {
 	b.linkPath = append(b.linkPath, name)
 	return b.super
@@ -0,0 +1,358 @@
+package chain
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Manifest lists the files a run produced with their hashes, so results can later be shown to
+// be unaltered. When signed, Signature is the base64 ed25519 signature of the manifest's JSON
+// encoding with Signature empty, made with the key matching PublicKey.
+type Manifest struct {
+	Chain     string          `json:"chain"`
+	Started   time.Time       `json:"started"`
+	Finished  time.Time       `json:"finished"`
+	Files     []ManifestEntry `json:"files"`
+	PublicKey string          `json:"public_key,omitempty"`
+	Signature string          `json:"signature,omitempty"`
+}
+
+// ManifestEntry describes one produced file. Producer is the path of the link, or the name of
+// the outputter, whose targets include the file.
+type ManifestEntry struct {
+	Path     string    `json:"path"`
+	Producer string    `json:"producer"`
+	SHA256   string    `json:"sha256"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// WithManifest makes the chain write a Manifest of the files its links and outputters
+// produced to path once Wait returns, including when the chain failed. Files are found through
+// the FileTargeter interface; targets that are directories, such as Docker download
+// directories and NoseyParker datastores, contribute every file within them that was created or
+// changed during the run. With a signing key the manifest is signed.
+func (c *BaseChain) WithManifest(path string, signingKey ed25519.PrivateKey) Chain {
+	c.manifestPath = path
+	c.manifestKey = signingKey
+	return c.super
+}
+
+// fileState is what a manifest compares to tell whether a file changed during a run.
+type fileState struct {
+	size     int64
+	modified time.Time
+}
+
+// fileSnapshot records the files under link and outputter targets before they start, so
+// files left over from earlier runs can be left out of the manifest. A chain shares its
+// snapshot with its nested chains.
+type fileSnapshot struct {
+	mu    sync.Mutex
+	files map[string]fileState
+}
+
+func (s *fileSnapshot) record(targets []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, target := range targets {
+		filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return nil
+			}
+			if _, ok := s.files[path]; ok {
+				return nil // already recorded by an earlier link targeting it
+			}
+			if info, err := d.Info(); err == nil {
+				s.files[path] = fileState{size: info.Size(), modified: info.ModTime()}
+			}
+			return nil
+		})
+	}
+}
+
+// unchanged reports whether the file at path is as it was recorded.
+func (s *fileSnapshot) unchanged(path string, info fs.FileInfo) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before, ok := s.files[path]
+	return ok && info.Size() == before.size && info.ModTime().Equal(before.modified)
+}
+
+// snapshotManifest starts the chain's file snapshot, if it writes a manifest.
+func (c *BaseChain) snapshotManifest() {
+	if c.manifestPath != "" && c.manifestPrior == nil {
+		c.manifestPrior = &fileSnapshot{files: map[string]fileState{}}
+	}
+}
+
+// snapshotTargets records the files under the targets of a link or outputter that is about to
+// start, or shares the chain's snapshot with a nested chain.
+func (c *BaseChain) snapshotTargets(v any) {
+	if c.manifestPrior == nil {
+		return
+	}
+
+	if link, ok := v.(Link); ok {
+		if nested, ok := asBaseChain(link); ok {
+			nested.manifestPrior = c.manifestPrior
+			return
+		}
+	}
+
+	if targeter, ok := v.(FileTargeter); ok {
+		c.manifestPrior.record(targeter.TargetFiles())
+	}
+}
+
+// writeManifest writes the chain's manifest, if it has one, the first time the chain finishes.
+func (c *BaseChain) writeManifest() {
+	if c.manifestPath == "" {
+		return
+	}
+
+	c.manifestOnce.Do(func() {
+		if err := c.buildManifest().WriteFile(c.manifestPath, c.manifestKey); err != nil {
+			c.handleError(fmt.Errorf("failed to write manifest: %w", err))
+		}
+	})
+}
+
+func (c *BaseChain) buildManifest() *Manifest {
+	manifest := &Manifest{
+		Chain:    c.Name(),
+		Started:  c.startedAt,
+		Finished: time.Now(),
+		Files:    []ManifestEntry{},
+	}
+
+	seen := map[string]bool{c.manifestPath: true}
+	for _, target := range c.manifestTargets() {
+		for _, entry := range manifestEntries(target, c.manifestPrior, c.Logger.Debug) {
+			if !seen[entry.Path] {
+				seen[entry.Path] = true
+				manifest.Files = append(manifest.Files, entry)
+			}
+		}
+	}
+
+	return manifest
+}
+
+// manifestTarget is a file or directory that a link or outputter targets.
+type manifestTarget struct {
+	path     string
+	producer string
+}
+
+// manifestTargets returns the targets of the chain's links and outputters.
+func (c *BaseChain) manifestTargets() []manifestTarget {
+	targets := []manifestTarget{}
+	add := func(producer string, paths []string) {
+		for _, path := range paths {
+			targets = append(targets, manifestTarget{path: path, producer: producer})
+		}
+	}
+
+	for _, link := range leafLinks(c) {
+		if targeter, ok := link.(FileTargeter); ok {
+			add(linkPath(link), targeter.TargetFiles())
+		}
+	}
+
+	for _, outputter := range c.outputters {
+		if targeter, ok := outputter.(FileTargeter); ok {
+			add(outputter.Name(), targeter.TargetFiles())
+		}
+	}
+
+	return targets
+}
+
+// manifestEntries hashes target, or every regular file under it if it is a directory. Targets
+// that weren't created, and files that are unchanged since prior recorded them, are left out.
+func manifestEntries(target manifestTarget, prior *fileSnapshot, debug func(string, ...any)) []ManifestEntry {
+	entries := []ManifestEntry{}
+	err := filepath.WalkDir(target.path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		if prior != nil {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if prior.unchanged(path, info) {
+				return nil // left over from an earlier run
+			}
+		}
+
+		entry, err := hashManifestEntry(path, target.producer)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		debug("skipping manifest target", "target", target.path, "producer", target.producer, "error", err)
+	}
+	return entries
+}
+
+func hashManifestEntry(path, producer string) (ManifestEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ManifestEntry{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return ManifestEntry{}, err
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return ManifestEntry{}, err
+	}
+
+	return ManifestEntry{
+		Path:     path,
+		Producer: producer,
+		SHA256:   hex.EncodeToString(hash.Sum(nil)),
+		Size:     size,
+		Modified: info.ModTime().UTC(),
+	}, nil
+}
+
+// WriteFile signs the manifest if signingKey isn't nil, and writes it to path as JSON.
+func (m *Manifest) WriteFile(path string, signingKey ed25519.PrivateKey) error {
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].Path < m.Files[j].Path
+	})
+
+	if signingKey != nil {
+		m.PublicKey = base64.StdEncoding.EncodeToString(signingKey.Public().(ed25519.PublicKey))
+		m.Signature = ""
+
+		signed, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		m.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(signingKey, signed))
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// ReadManifest reads a manifest written by WriteFile.
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// Verify checks that every file listed in the manifest still has its recorded hash and size.
+// If publicKey isn't nil, the manifest must also carry a valid signature made with its
+// private key.
+func (m *Manifest) Verify(publicKey ed25519.PublicKey) error {
+	if publicKey != nil {
+		if err := m.verifySignature(publicKey); err != nil {
+			return err
+		}
+	}
+
+	errs := []error{}
+	for _, entry := range m.Files {
+		actual, err := hashManifestEntry(entry.Path, entry.Producer)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if actual.SHA256 != entry.SHA256 || actual.Size != entry.Size {
+			errs = append(errs, fmt.Errorf("%s has been modified: expected sha256 %s, got %s", entry.Path, entry.SHA256, actual.SHA256))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Manifest) verifySignature(publicKey ed25519.PublicKey) error {
+	if m.Signature == "" {
+		return fmt.Errorf("manifest is not signed")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid manifest signature: %w", err)
+	}
+
+	unsigned := *m
+	unsigned.Signature = ""
+	signed, err := json.Marshal(&unsigned)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(publicKey, signed, signature) {
+		return fmt.Errorf("manifest signature is invalid")
+	}
+	return nil
+}
+
+// LoadSigningKey reads a PEM encoded PKCS #8 ed25519 private key, such as one generated by
+// `openssl genpkey -algorithm ed25519`.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key in %s: %w", path, err)
+	}
+
+	signingKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key in %s is a %T, not an ed25519 key", path, key)
+	}
+	return signingKey, nil
+}
@@ -0,0 +1,253 @@
+package chain_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/output"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type artifactLink struct {
+	*chain.Base
+}
+
+func newArtifactLink(configs ...cfg.Config) chain.Link {
+	a := &artifactLink{}
+	a.Base = chain.NewBase(a, configs...)
+	return a
+}
+
+func (a *artifactLink) Params() []cfg.Param {
+	return []cfg.Param{
+		cfg.NewParam[string]("artifact-dir", "directory to write artifacts to"),
+	}
+}
+
+func (a *artifactLink) TargetFiles() []string {
+	dir, _ := cfg.As[string](a.Arg("artifact-dir"))
+	return []string{dir}
+}
+
+func (a *artifactLink) Process(input string) error {
+	dir, _ := cfg.As[string](a.Arg("artifact-dir"))
+	if err := os.WriteFile(filepath.Join(dir, input+".tar"), []byte(input), 0644); err != nil {
+		return err
+	}
+	a.Send(input)
+	return nil
+}
+
+// slowReportOutputter writes its report a while after being completed.
+type slowReportOutputter struct {
+	*chain.BaseOutputter
+	path string
+}
+
+func newSlowReportOutputter(path string) *slowReportOutputter {
+	o := &slowReportOutputter{path: path}
+	o.BaseOutputter = chain.NewBaseOutputter(o)
+	return o
+}
+
+func (o *slowReportOutputter) TargetFiles() []string {
+	return []string{o.path}
+}
+
+func (o *slowReportOutputter) Output(val any) error {
+	return nil
+}
+
+func (o *slowReportOutputter) Complete() error {
+	time.Sleep(100 * time.Millisecond)
+	return os.WriteFile(o.path, []byte("report"), 0644)
+}
+
+// dirOutputter targets a directory it never writes to.
+type dirOutputter struct {
+	*chain.BaseOutputter
+	dir string
+}
+
+func newDirOutputter(dir string) *dirOutputter {
+	o := &dirOutputter{dir: dir}
+	o.BaseOutputter = chain.NewBaseOutputter(o)
+	return o
+}
+
+func (o *dirOutputter) TargetFiles() []string {
+	return []string{o.dir}
+}
+
+func (o *dirOutputter) Output(val any) error {
+	return nil
+}
+
+func TestChain_Manifest(t *testing.T) {
+	dir := t.TempDir()
+	artifacts := filepath.Join(dir, "artifacts")
+	require.NoError(t, os.Mkdir(artifacts, 0755))
+	manifestPath := filepath.Join(dir, "manifest.json")
+	jsonPath := filepath.Join(dir, "out.json")
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	c := chain.NewChain(
+		newArtifactLink(),
+	).WithConfigs(
+		cfg.WithArg("artifact-dir", artifacts),
+		cfg.WithArg("jsonoutfile", jsonPath),
+	).WithOutputters(
+		output.NewJSONOutputter(),
+	).WithManifest(manifestPath, private)
+
+	c.Send("alpine", "nginx")
+	c.Close()
+	c.Wait()
+	require.NoError(t, c.Error())
+
+	manifest, err := chain.ReadManifest(manifestPath)
+	require.NoError(t, err)
+	require.Len(t, manifest.Files, 3)
+
+	assert.Equal(t, filepath.Join(artifacts, "alpine.tar"), manifest.Files[0].Path)
+	assert.Contains(t, manifest.Files[0].Producer, "artifactLink")
+	assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256([]byte("alpine"))), manifest.Files[0].SHA256)
+	assert.Equal(t, int64(len("alpine")), manifest.Files[0].Size)
+	assert.Equal(t, filepath.Join(artifacts, "nginx.tar"), manifest.Files[1].Path)
+	assert.Equal(t, jsonPath, manifest.Files[2].Path)
+	assert.Equal(t, "*output.JSONOutputter", manifest.Files[2].Producer)
+	assert.False(t, manifest.Finished.Before(manifest.Started))
+
+	require.NoError(t, manifest.Verify(public))
+
+	require.NoError(t, os.WriteFile(filepath.Join(artifacts, "nginx.tar"), []byte("tampered"), 0644))
+	assert.ErrorContains(t, manifest.Verify(nil), "nginx.tar has been modified")
+
+	manifest.Files = manifest.Files[:1]
+	assert.ErrorContains(t, manifest.Verify(public), "signature is invalid")
+}
+
+func TestModule_Manifest(t *testing.T) {
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "manifest.json")
+	jsonPath := filepath.Join(dir, "out.json")
+
+	module := chain.NewModule(cfg.NewMetadata("manifest", "writes a manifest").WithChainInputParam("images")).
+		WithLinks(newArtifactLink).
+		WithOutputters(output.NewJSONOutputter).
+		WithInputParam(cfg.NewParam[[]string]("images", "images to download")).
+		WithManifest(manifestPath, nil)
+
+	err := module.Run(
+		cfg.WithArg("images", []string{"alpine"}),
+		cfg.WithArg("artifact-dir", dir),
+		cfg.WithArg("jsonoutfile", jsonPath),
+	)
+	require.NoError(t, err)
+
+	manifest, err := chain.ReadManifest(manifestPath)
+	require.NoError(t, err)
+	assert.Empty(t, manifest.Signature)
+	require.NoError(t, manifest.Verify(nil))
+
+	paths := []string{}
+	for _, entry := range manifest.Files {
+		paths = append(paths, entry.Path)
+	}
+	assert.Equal(t, []string{filepath.Join(dir, "alpine.tar"), jsonPath}, paths, "the manifest must not list itself")
+}
+
+func TestChain_Manifest_LeftoverFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		newChain func(links ...chain.Link) chain.Chain
+	}{
+		{name: "chain", newChain: chain.NewChain},
+		{name: "multichain", newChain: chain.NewMulti},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			reports := t.TempDir()
+			manifestPath := filepath.Join(dir, "manifest.json")
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "old.tar"), []byte("old"), 0644))
+			require.NoError(t, os.WriteFile(filepath.Join(reports, "old.txt"), []byte("old"), 0644))
+
+			c := tt.newChain(
+				newArtifactLink(),
+			).WithConfigs(
+				cfg.WithArg("artifact-dir", dir),
+			).WithOutputters(
+				newDirOutputter(reports),
+			).WithManifest(manifestPath, nil)
+
+			c.Send("alpine")
+			c.Close()
+			c.Wait()
+			require.NoError(t, c.Error())
+
+			manifest, err := chain.ReadManifest(manifestPath)
+			require.NoError(t, err)
+			require.Len(t, manifest.Files, 1, "files left over from earlier runs should not be listed")
+			assert.Equal(t, filepath.Join(dir, "alpine.tar"), manifest.Files[0].Path)
+		})
+	}
+}
+
+func TestChain_Manifest_OnError(t *testing.T) {
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "manifest.json")
+	reportPath := filepath.Join(dir, "report.txt")
+
+	c := chain.NewChain(
+		newArtifactLink(),
+		basics.NewErrorLink(),
+	).WithConfigs(
+		cfg.WithArg("artifact-dir", dir),
+		cfg.WithArg("errorAt", "process"),
+	).WithOutputters(
+		newSlowReportOutputter(reportPath),
+	).WithStrictness(chain.Strict).WithManifest(manifestPath, nil)
+
+	c.Send("alpine")
+	c.Close()
+	require.Eventually(t, func() bool { return c.Error() != nil }, time.Second, time.Millisecond, "the chain should fail before Wait is called")
+	c.Wait()
+
+	manifest, err := chain.ReadManifest(manifestPath)
+	require.NoError(t, err, "a failed run should still write its manifest")
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, filepath.Join(dir, "alpine.tar"), manifest.Files[0].Path)
+	assert.Equal(t, reportPath, manifest.Files[1].Path, "outputters should complete before the manifest is written")
+	assert.NoError(t, manifest.Verify(nil), "the manifest should hash the files as they were left")
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+
+	loaded, err := chain.LoadSigningKey(path)
+	require.NoError(t, err)
+	assert.True(t, private.Equal(loaded))
+}
@@ -0,0 +1,101 @@
+package chain
+
+import (
+	"time"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cherrors"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/metrics"
+)
+
+// Metrics recorded by chains with a registry.
+const (
+	MetricItemsProcessed  = "janus_link_items_processed_total"
+	MetricProcessDuration = "janus_link_process_duration_seconds"
+	MetricErrors          = "janus_errors_total"
+	MetricOutputterWrites = "janus_outputter_writes_total"
+	MetricCommandDuration = "janus_command_duration_seconds"
+	MetricRegistryBytes   = "janus_registry_bytes_downloaded_total"
+)
+
+// WithMetrics makes the chain record metrics in registry: items processed, Process latency and
+// errors by class per link, outputter writes and errors, and the duration of the commands links
+// run. Nested chains record in the same registry unless given their own. Serve the registry
+// with registry.Handler().
+func (c *BaseChain) WithMetrics(registry *metrics.Registry) Chain {
+	c.Base.metrics = registry
+	return c.super
+}
+
+// Metrics returns the registry the link records metrics in, or nil if its chain has none.
+// Recording metrics in a nil registry does nothing, so links can record their own metrics
+// unconditionally.
+func (b *Base) Metrics() *metrics.Registry {
+	return b.metrics
+}
+
+func (b *Base) setMetrics(registry *metrics.Registry) {
+	if b.metrics == nil {
+		b.metrics = registry
+	}
+}
+
+// instrumentChild makes child record metrics in the chain's registry.
+func (c *BaseChain) instrumentChild(child Link) {
+	if c.metrics != nil {
+		child.setMetrics(c.metrics)
+	}
+}
+
+func (b *Base) recordProcess(duration time.Duration, err error) {
+	if b.metrics == nil {
+		return
+	}
+
+	link := linkPath(b.super)
+	b.metrics.Counter(MetricItemsProcessed, "Items processed by each link.", "link").Inc(link)
+	b.metrics.Histogram(MetricProcessDuration, "Duration of each link's Process calls.", metrics.DefaultBuckets, "link").Observe(duration.Seconds(), link)
+	if err != nil {
+		recordError(b.metrics, link, errorClass(err))
+	}
+}
+
+func (b *Base) recordCommand(executable string, duration time.Duration, err error) {
+	if b.metrics == nil {
+		return
+	}
+
+	status := "ok"
+	if err != nil {
+		status = "error"
+		recordError(b.metrics, linkPath(b.super), "command")
+	}
+	b.metrics.Histogram(MetricCommandDuration, "Duration of external commands run by links.", metrics.DefaultBuckets, "executable", "status").
+		Observe(duration.Seconds(), executable, status)
+}
+
+func (c *BaseChain) recordOutput(outputter Outputter, err error) {
+	if c.metrics == nil {
+		return
+	}
+
+	if err != nil {
+		recordError(c.metrics, outputter.Name(), "outputter")
+		return
+	}
+	c.metrics.Counter(MetricOutputterWrites, "Items written by each outputter.", "outputter").Inc(outputter.Name())
+}
+
+func recordError(registry *metrics.Registry, source, class string) {
+	registry.Counter(MetricErrors, "Errors by the link or outputter reporting them and their class.", "source", "class").Inc(source, class)
+}
+
+func errorClass(err error) string {
+	switch err.(type) {
+	case *cherrors.ConversionError:
+		return "conversion"
+	case *cherrors.DebugError:
+		return "debug"
+	default:
+		return "process"
+	}
+}
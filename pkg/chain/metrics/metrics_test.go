@@ -0,0 +1,78 @@
+package metrics_test
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	items := registry.Counter("items_total", "Items seen.", "link")
+	items.Inc("b")
+	items.Add(2, "a")
+	items.Inc("b")
+	registry.Counter("items_total", "Items seen.", "link").Inc("a")
+	registry.Counter("bytes_total", "Bytes read.").Add(1024)
+
+	latency := registry.Histogram("latency_seconds", "Latency.", []float64{1, 0.1}, "link")
+	latency.Observe(0.05, `say "hi"`)
+	latency.Observe(0.5, `say "hi"`)
+	latency.Observe(5, `say "hi"`)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, registry.WriteText(buf))
+
+	assert.Equal(t, `# HELP bytes_total Bytes read.
+# TYPE bytes_total counter
+bytes_total 1024
+# HELP items_total Items seen.
+# TYPE items_total counter
+items_total{link="a"} 3
+items_total{link="b"} 2
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{link="say \"hi\"",le="0.1"} 1
+latency_seconds_bucket{link="say \"hi\"",le="1"} 2
+latency_seconds_bucket{link="say \"hi\"",le="+Inf"} 3
+latency_seconds_sum{link="say \"hi\""} 5.55
+latency_seconds_count{link="say \"hi\""} 3
+`, buf.String())
+}
+
+func TestRegistry_Conflicts(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.Counter("items_total", "Items seen.", "link")
+
+	assert.Panics(t, func() { registry.Histogram("items_total", "Items seen.", metrics.DefaultBuckets, "link") })
+	assert.Panics(t, func() { registry.Counter("items_total", "Items seen.", "outputter") })
+	assert.Panics(t, func() { registry.Counter("items_total", "Items seen.", "link").Inc() })
+}
+
+func TestRegistry_Nil(t *testing.T) {
+	var registry *metrics.Registry
+
+	assert.NotPanics(t, func() {
+		registry.Counter("items_total", "Items seen.", "link").Inc("a")
+		registry.Histogram("latency_seconds", "Latency.", metrics.DefaultBuckets).Observe(1)
+	})
+	assert.NoError(t, registry.WriteText(io.Discard))
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.Counter("items_total", "Items seen.").Inc()
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "items_total 1\n")
+}
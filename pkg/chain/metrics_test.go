@@ -0,0 +1,61 @@
+package chain_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/metrics"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain_Metrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	outputter := NewTestRecordingOutputter()
+
+	c := chain.NewChain(
+		basics.NewStrLink(),
+		chain.NewChain(
+			basics.NewEchoLink(),
+		).WithName("nested"),
+	).WithName("measured").WithOutputters(outputter).WithMetrics(registry).WithStrictness(chain.Lax)
+
+	c.Send("one", "fail", "two")
+	c.Close()
+	c.Wait()
+	require.NoError(t, c.Error())
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, registry.WriteText(buf))
+	text := buf.String()
+
+	assert.Contains(t, text, `janus_link_items_processed_total{link="measured/*basics.StrLink"} 3`)
+	assert.Contains(t, text, `janus_link_items_processed_total{link="measured/nested/*basics.EchoLink"} 3`, "nested chains should record in their parent's registry")
+	assert.Contains(t, text, `janus_link_process_duration_seconds_count{link="measured/*basics.StrLink"} 3`)
+	assert.Contains(t, text, `janus_outputter_writes_total{outputter="*chain_test.TestRecordingOutputter"} 2`)
+	assert.Contains(t, text, `janus_errors_total{source="*chain_test.TestRecordingOutputter",class="outputter"} 1`)
+}
+
+func TestChain_MetricsErrorsAndCommands(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	c := chain.NewChain(
+		mocks.NewExecutor(cfg.WithArg("cmd", "sh"), cfg.WithArg("args", []string{"-c", "exit 1"})),
+	).WithName("commands").WithMetrics(registry).WithStrictness(chain.Lax)
+
+	c.Send("run", "run")
+	c.Close()
+	c.Wait()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, registry.WriteText(buf))
+	text := buf.String()
+
+	assert.Contains(t, text, `janus_command_duration_seconds_count{executable="sh",status="error"} 2`)
+	assert.Contains(t, text, `janus_errors_total{source="commands/*mocks.Executor",class="command"} 2`)
+	assert.Contains(t, text, `janus_errors_total{source="commands/*mocks.Executor",class="process"} 2`)
+}
@@ -1,11 +1,24 @@
 package chain
 
 import (
+	"crypto/ed25519"
 	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/metrics"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// DryRunParam is the reserved param that makes a module write its plan instead of running,
+// like WithDryRun, e.g. with -dry-run on the command line. Modules don't need to declare it. The
+// plan is written to the module's WithDryRun writer, or to stdout.
+const DryRunParam = "dry-run"
+
 type LinkConstructor func(...cfg.Config) Link
 
 type OutputterConstructor func(...cfg.Config) Outputter
@@ -29,7 +42,18 @@ type Module struct {
 	configs      []cfg.Config
 	inputParam   cfg.Param
 	autoRun      bool
+	dryRun       io.Writer
 	strictness   Strictness
+	verifiers    []cfg.PermissionVerifier
+	credentials  []cfg.CredentialProvider
+	manifestPath string
+	manifestKey  ed25519.PrivateKey
+	envelopes    bool
+	tracer       trace.TracerProvider
+	metrics      *metrics.Registry
+	progress     []ProgressReporter
+	interval     time.Duration
+	handlers     []EventHandler
 	err          error
 	*cfg.ParamHolder
 }
@@ -62,12 +86,20 @@ func (m *Module) WithInputParam(param cfg.Param) *Module {
 }
 
 // WithAutoRun configures the module to automatically run without requiring
-// an inputParam value.
+// an inputParam value. The chain is sent a single input: AutoRun if its first link processes
+// AutoRun, or the string "autorun".
 func (m *Module) WithAutoRun() *Module {
 	m.autoRun = true
 	return m
 }
 
+// WithDryRun configures the module to write its plan to w instead of running when .Run() is
+// called. No link is initialized and no input is processed.
+func (m *Module) WithDryRun(w io.Writer) *Module {
+	m.dryRun = w
+	return m
+}
+
 func (m *Module) WithParams(params ...cfg.Param) *Module {
 	for _, param := range params {
 		m.SetParams(param)
@@ -85,13 +117,85 @@ func (m *Module) WithStrictness(strictness Strictness) *Module {
 	return m
 }
 
+// WithCredentialProviders sets the providers the module's links resolve their credentials
+// from. See BaseChain.WithCredentialProviders.
+func (m *Module) WithCredentialProviders(providers ...cfg.CredentialProvider) *Module {
+	m.credentials = providers
+	return m
+}
+
+// WithManifest makes the module write a manifest of the files it produced to path once it
+// has run, signed with signingKey if it isn't nil. See BaseChain.WithManifest.
+func (m *Module) WithManifest(path string, signingKey ed25519.PrivateKey) *Module {
+	m.manifestPath = path
+	m.manifestKey = signingKey
+	return m
+}
+
+// WithEnvelopes makes the module track the lineage of its items. See BaseChain.WithEnvelopes.
+func (m *Module) WithEnvelopes() *Module {
+	m.envelopes = true
+	return m
+}
+
+// WithTracerProvider makes the module's chain record OpenTelemetry spans with provider. See
+// BaseChain.WithTracerProvider.
+func (m *Module) WithTracerProvider(provider trace.TracerProvider) *Module {
+	m.tracer = provider
+	return m
+}
+
+// WithMetrics makes the module's chain record metrics in registry. See BaseChain.WithMetrics.
+func (m *Module) WithMetrics(registry *metrics.Registry) *Module {
+	m.metrics = registry
+	return m
+}
+
+// WithProgress makes the module's chain report its progress to reporters every interval. The
+// events count the module's inputs when its input source can count them. See
+// BaseChain.WithProgress.
+func (m *Module) WithProgress(interval time.Duration, reporters ...ProgressReporter) *Module {
+	m.interval = interval
+	m.progress = reporters
+	return m
+}
+
+// OnEvent calls handler with the events of the module's chain. See BaseChain.OnEvent.
+func (m *Module) OnEvent(handler EventHandler) *Module {
+	m.handlers = append(m.handlers, handler)
+	return m
+}
+
+// WithPermissionVerifiers makes the module verify the permissions its links require before
+// processing any input. See BaseChain.WithPermissionVerifiers.
+func (m *Module) WithPermissionVerifiers(verifiers ...cfg.PermissionVerifier) *Module {
+	m.verifiers = verifiers
+	return m
+}
+
+// Run runs the module to completion. The module's input param may hold either a []string or
+// an InputSource; InputSources are streamed into the chain as they are read.
 func (m *Module) Run(configs ...cfg.Config) error {
+	return m.run(nil, configs...)
+}
+
+// RunWithInputs runs the module to completion, streaming inputs from source instead of
+// reading them from the module's input param.
+func (m *Module) RunWithInputs(source InputSource, configs ...cfg.Config) error {
+	if source == nil {
+		m.err = fmt.Errorf("input source for module %q is nil", m.metadata.Name)
+		return m.err
+	}
+	return m.run(source, configs...)
+}
+
+func (m *Module) run(source InputSource, configs ...cfg.Config) error {
 	if len(m.outputters) == 0 {
 		m.err = fmt.Errorf("module must have outputters to call .Run(). %s has no outputters", m.metadata.Name)
 		return m.err
 	}
 
-	if m.metadata.InputParam == "" && !m.autoRun {
+	if source == nil && m.metadata.InputParam == "" && !m.autoRun {
 		m.err = fmt.Errorf("input parameter or AutoRun is required to call .Run(), but module %q has no input parameter", m.metadata.Name)
 		return m.err
 	}
@@ -100,38 +204,171 @@ func (m *Module) Run(configs ...cfg.Config) error {
 	c.WithConfigs(append(m.configs, configs...)...)
 	c.resetParams()
 
-	if !m.autoRun {
-		if !c.HasParam(m.metadata.InputParam) {
-			m.err = fmt.Errorf("module %q specifies %q as input parameter, but module.Params() does not contain %q", m.metadata.Name, m.metadata.InputParam, m.metadata.InputParam)
-			return m.err
-		}
-
-		input := c.Arg(m.metadata.InputParam)
-		if input == nil {
-			m.err = fmt.Errorf("input parameter %q is unset for module %q", m.metadata.InputParam, m.metadata.Name)
-			return m.err
-		}
+	dryRun, err := m.dryRunWriter(c)
+	if err != nil {
+		m.err = err
+		return m.err
+	}
+	if dryRun != nil {
+		return m.writePlan(dryRun, c, source)
+	}
 
-		inputSlice, ok := input.([]string)
-		if !ok {
-			m.err = fmt.Errorf("module input parameter %q must be a slice of strings. %q is a %T", m.metadata.InputParam, input, input)
+	if source == nil {
+		var err error
+		source, err = m.inputSource(c)
+		if err != nil {
+			m.err = err
 			return m.err
 		}
+	}
 
-		for _, input := range inputSlice {
-			c.Send(input)
-		}
-	} else {
-		c.Send("autorun")
+	if len(m.progress) > 0 {
+		c.WithExpectedInputs(m.inputCount(c, source))
 	}
 
+	sendErr := m.sendInputs(c, source)
+
 	c.Close()
 	c.Wait()
 
 	m.err = c.Error()
+	if sendErr != nil && m.err == nil {
+		m.err = sendErr
+	}
 	return m.err
 }
 
+// Plan resolves the module's args and describes its links, outputters, required binaries and
+// permissions, and number of inputs, without running anything.
+func (m *Module) Plan(configs ...cfg.Config) (*Plan, error) {
+	c := m.New()
+	c.WithConfigs(append(m.configs, configs...)...)
+	return m.plan(c, nil)
+}
+
+func (m *Module) plan(c Chain, source InputSource) (*Plan, error) {
+	plan, err := c.Plan()
+	if plan == nil {
+		return nil, err
+	}
+
+	plan.Name = m.metadata.Name
+	plan.Inputs = m.inputCount(c, source)
+	return plan, err
+}
+
+// dryRunWriter returns the writer to write the module's plan to, or nil if the module should
+// run: the WithDryRun writer, or stdout if the reserved dry-run param is set.
+func (m *Module) dryRunWriter(c Chain) (io.Writer, error) {
+	if m.dryRun != nil {
+		return m.dryRun, nil
+	}
+
+	holder, ok := c.(interface{ ReservedArg(string) (any, bool) })
+	if !ok {
+		return nil, nil
+	}
+	value, ok := holder.ReservedArg(DryRunParam)
+	if !ok {
+		return nil, nil
+	}
+
+	enabled, err := dryRunFromArg(value)
+	if err != nil || !enabled {
+		return nil, err
+	}
+	return os.Stdout, nil
+}
+
+func dryRunFromArg(value any) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		if v == "" {
+			return true, nil // a bare -dry-run flag
+		}
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s value %q: %w", DryRunParam, v, err)
+		}
+		return enabled, nil
+	default:
+		return false, fmt.Errorf("invalid %s value of type %T", DryRunParam, value)
+	}
+}
+
+func (m *Module) writePlan(w io.Writer, c Chain, source InputSource) error {
+	plan, err := m.plan(c, source)
+	if plan != nil {
+		if _, writeErr := io.WriteString(w, plan.String()); writeErr != nil && err == nil {
+			err = writeErr
+		}
+	}
+
+	m.err = err
+	return m.err
+}
+
+func (m *Module) inputCount(c Chain, source InputSource) int {
+	if source == nil {
+		var err error
+		if source, err = m.inputSource(c); err != nil {
+			return UnknownInputCount
+		}
+	}
+
+	countable, ok := source.(CountableInputSource)
+	if !ok {
+		return UnknownInputCount
+	}
+
+	count, err := countable.Count()
+	if err != nil {
+		return UnknownInputCount
+	}
+	return count
+}
+
+func (m *Module) inputSource(c Chain) (InputSource, error) {
+	if m.autoRun {
+		return autoRunInputs(), nil
+	}
+
+	if !c.HasParam(m.metadata.InputParam) {
+		return nil, fmt.Errorf("module %q specifies %q as input parameter, but module.Params() does not contain %q", m.metadata.Name, m.metadata.InputParam, m.metadata.InputParam)
+	}
+
+	input := c.Arg(m.metadata.InputParam)
+	if input == nil {
+		return nil, fmt.Errorf("input parameter %q is unset for module %q", m.metadata.InputParam, m.metadata.Name)
+	}
+
+	switch input := input.(type) {
+	case []string:
+		return InputsFromSlice(input), nil
+	case InputSource:
+		return input, nil
+	default:
+		return nil, fmt.Errorf("module input parameter %q must be a slice of strings or an InputSource. %q is a %T", m.metadata.InputParam, input, input)
+	}
+}
+
+// sendInputs streams each input into the chain. Send blocks until the first link accepts the
+// input, so the source is never read faster than the chain can process it.
+func (m *Module) sendInputs(c Chain, source InputSource) error {
+	for input, err := range source.Inputs(firstLink(c)) {
+		if err != nil {
+			return fmt.Errorf("module %q failed to read input: %w", m.metadata.Name, err)
+		}
+
+		if err := c.Send(input); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *Module) New() Chain {
 	links := make([]Link, len(m.constructors))
 	for i, constructor := range m.constructors {
@@ -154,7 +391,26 @@ func (m *Module) New() Chain {
 		WithInputParam(m.inputParam).
 		WithOutputters(outputters...).
 		WithConfigs(moduleConfigs...).
-		WithStrictness(m.strictness)
+		WithStrictness(m.strictness).
+		WithPermissionVerifiers(m.verifiers...).
+		WithCredentialProviders(m.credentials...).
+		WithManifest(m.manifestPath, m.manifestKey)
+
+	if m.envelopes {
+		c.WithEnvelopes()
+	}
+	if m.tracer != nil {
+		c.WithTracerProvider(m.tracer)
+	}
+	if m.metrics != nil {
+		c.WithMetrics(m.metrics)
+	}
+	if len(m.progress) > 0 {
+		c.WithProgress(m.interval, m.progress...)
+	}
+	for _, handler := range m.handlers {
+		c.OnEvent(handler)
+	}
 
 	m.err = c.Error()
 	return c
@@ -190,6 +446,63 @@ func (m *Module) Params() []cfg.Param {
 	return allParams
 }
 
+// Schema describes the module's params as a JSON Schema document, suitable for building
+// input forms or validating args before they reach the module.
+func (m *Module) Schema() *cfg.Schema {
+	schema := cfg.NewSchema(m.Params()...)
+	schema.Title = m.metadata.Name
+	schema.Description = m.metadata.Description
+	schema.InputParam = m.metadata.InputParam
+	if len(m.metadata.Properties()) > 0 {
+		schema.Metadata = m.metadata.Properties()
+	}
+	return schema
+}
+
+func (m *Module) ValidateJSON(doc []byte) error {
+	if err := m.Schema().Validate(doc); err != nil {
+		return fmt.Errorf("module %q received invalid args: %w", m.metadata.Name, err)
+	}
+	return nil
+}
+
+// RunJSON validates a JSON args document against the module's schema and, if it is valid,
+// runs the module with those args. Args passed in configs take precedence over the document, and
+// required params set by configs or the module's own configs needn't be in it.
+func (m *Module) RunJSON(doc []byte, configs ...cfg.Config) error {
+	schema := m.Schema()
+	schema.Required = slices.DeleteFunc(schema.Required, m.suppliedBy(configs))
+	if err := schema.Validate(doc); err != nil {
+		m.err = fmt.Errorf("module %q received invalid args: %w", m.metadata.Name, err)
+		return m.err
+	}
+
+	return m.Run(append([]cfg.Config{cfg.WithJSONArgs(doc)}, configs...)...)
+}
+
+// suppliedBy reports whether the module's configs or configs set the named param, so a JSON
+// args document doesn't need to.
+func (m *Module) suppliedBy(configs []cfg.Config) func(string) bool {
+	c := m.New()
+	c.WithConfigs(append(m.configs, configs...)...)
+	c.resetParams()
+
+	sourcer, ok := c.(argSourcer)
+	return func(name string) bool {
+		if !ok {
+			return false
+		}
+		source := sourcer.ArgSource(name)
+		return source != cfg.ArgSourceUnset && source != cfg.ArgSourceDefault
+	}
+}
+
+// Policies generates a least-privilege policy for each platform the module's links require
+// permissions on, keyed by platform. See cfg.GeneratePolicies.
+func (m *Module) Policies() (map[cfg.Platform][]byte, error) {
+	return cfg.GeneratePolicies(m.metadata.Name, m.metadata.Description, m.New().Permissions())
+}
+
 func (m *Module) Error() error {
 	return m.err
 }
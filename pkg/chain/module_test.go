@@ -2,6 +2,7 @@ package chain_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"testing"
 
 	"github.com/praetorian-inc/janus-framework/pkg/chain"
@@ -224,6 +225,45 @@ func TestModule_AutoRun(t *testing.T) {
 	assert.Equal(t, "autorun!\n", w.String())
 }
 
+// autoRunLink sends a single item when it is auto run.
+type autoRunLink struct {
+	*chain.Base
+}
+
+func newAutoRunLink(configs ...cfg.Config) chain.Link {
+	l := &autoRunLink{}
+	l.Base = chain.NewBase(l, configs...)
+	return l
+}
+
+func (l *autoRunLink) Process(chain.AutoRun) error {
+	return l.Send("ran")
+}
+
+func TestModule_AutoRun_Typed(t *testing.T) {
+	w := &bytes.Buffer{}
+
+	module := chain.NewModule(
+		cfg.NewMetadata(
+			"AutoRun",
+			"AutoRun test",
+		),
+	).WithLinks(
+		newAutoRunLink,
+		basics.NewStrLink,
+	).WithConfigs(
+		cfg.WithArg("writer", w),
+		cfg.WithArg("strOp", func(s string) string { return s + "!" }),
+	).WithOutputters(
+		output.NewWriterOutputter,
+	).WithAutoRun()
+
+	module.Run()
+
+	assert.NoError(t, module.Error())
+	assert.Equal(t, "ran!\n", w.String(), "a first link that processes AutoRun should be sent AutoRun")
+}
+
 func TestModule_WithStrictness_Lax(t *testing.T) {
 	w := &bytes.Buffer{}
 
@@ -272,3 +312,119 @@ func TestModule_WithStrictness_Moderate(t *testing.T) {
 	assert.Error(t, err, "Moderate strictness should error on ProcessError")
 	assert.Error(t, moderateModule.Error())
 }
+
+func TestModule_Schema(t *testing.T) {
+	module := chain.NewModule(
+		cfg.NewMetadata(
+			"test",
+			"test description",
+		).WithChainInputParam("strings").WithProperty("platform", "aws"),
+	).WithLinks(
+		basics.NewStrLink,
+	).WithInputParam(
+		cfg.NewParam[[]string]("strings", "strings to process").AsRequired().WithShortcode("s"),
+	).WithOutputters(
+		output.NewWriterOutputter,
+	)
+
+	schema := module.Schema()
+	assert.Equal(t, "test", schema.Title)
+	assert.Equal(t, "test description", schema.Description)
+	assert.Equal(t, "strings", schema.InputParam)
+	assert.Equal(t, map[string]any{"platform": "aws"}, schema.Metadata)
+	assert.Equal(t, []string{"strings"}, schema.Required)
+
+	require.Contains(t, schema.Properties, "strings")
+	assert.Equal(t, "array", schema.Properties["strings"].Type)
+	assert.Equal(t, "s", schema.Properties["strings"].Shortcode)
+	assert.NotContains(t, schema.Properties, "writer", "io.Writer cannot be expressed in JSON")
+	assert.NotContains(t, schema.Properties, "strOp", "functions cannot be expressed in JSON")
+}
+
+func TestModule_RunJSON(t *testing.T) {
+	w := &bytes.Buffer{}
+
+	module := chain.NewModule(
+		cfg.NewMetadata(
+			"test",
+			"test",
+		).WithChainInputParam("strings"),
+	).WithLinks(
+		basics.NewStrLink,
+		basics.NewStrIntLink,
+	).WithConfigs(
+		cfg.WithArg("writer", w),
+	).WithInputParam(
+		cfg.NewParam[[]string]("strings", "strings to process").AsRequired(),
+	).WithOutputters(
+		output.NewWriterOutputter,
+	)
+
+	err := module.RunJSON([]byte(`{"strings": [1, 2]}`))
+	assert.ErrorContains(t, err, `parameter "strings": item at index 0: expected string, got number`)
+	assert.Empty(t, w.String(), "invalid args must not start the module")
+
+	err = module.RunJSON([]byte(`{"strings": ["1", "2", "3"]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "1\n2\n3\n", w.String())
+}
+
+func TestModule_RunJSON_RequiredArgFromConfigs(t *testing.T) {
+	w := &bytes.Buffer{}
+
+	module := chain.NewModule(
+		cfg.NewMetadata(
+			"test",
+			"test",
+		).WithChainInputParam("strings"),
+	).WithLinks(
+		basics.NewParamsLink,
+	).WithConfigs(
+		cfg.WithArg("writer", w),
+	).WithInputParam(
+		cfg.NewParam[[]string]("strings", "strings to process").AsRequired(),
+	).WithOutputters(
+		output.NewWriterOutputter,
+	)
+
+	err := module.RunJSON([]byte(`{"strings": ["1"]}`))
+	assert.ErrorContains(t, err, `parameter "required" is required`)
+
+	err = module.RunJSON([]byte(`{"strings": ["1"]}`), cfg.WithArg("required", "from config"))
+	assert.NoError(t, err, "a required arg supplied in configs should not be required in the document")
+}
+
+func TestModule_Policies(t *testing.T) {
+	module := chain.NewModule(
+		cfg.NewMetadata(
+			"policy-test",
+			"policy test",
+		),
+	).WithLinks(
+		func(configs ...cfg.Config) chain.Link {
+			return basics.NewPermissionsLink(configs...).WithPermissions(
+				cfg.NewPermission(cfg.AWS, "s3:ListBucket"),
+				cfg.NewPermission(cfg.GCP, "storage.buckets.list"),
+			)
+		},
+		func(configs ...cfg.Config) chain.Link {
+			return basics.NewPermissionsLink(configs...).WithPermissions(
+				cfg.NewPermission(cfg.AWS, "s3:GetObject"),
+				cfg.NewPermission(cfg.AWS, "s3:ListBucket"),
+			)
+		},
+	)
+
+	policies, err := module.Policies()
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+
+	var aws cfg.AWSPolicyDocument
+	require.NoError(t, json.Unmarshal(policies[cfg.AWS], &aws))
+	assert.Equal(t, []string{"s3:GetObject", "s3:ListBucket"}, aws.Statement[0].Action)
+
+	var gcp cfg.GCPCustomRole
+	require.NoError(t, json.Unmarshal(policies[cfg.GCP], &gcp))
+	assert.Equal(t, "policy-test", gcp.Title)
+	assert.Equal(t, []string{"storage.buckets.list"}, gcp.IncludedPermissions)
+}
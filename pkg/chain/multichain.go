@@ -2,6 +2,7 @@ package chain
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/praetorian-inc/janus-framework/pkg/util"
 )
@@ -21,6 +22,7 @@ func NewMulti(chains ...Link) Chain {
 	}
 	m.BaseChain.Base = NewBase(m)
 	m.super = m
+	m.expectedInputs.Store(UnknownInputCount)
 
 	for _, chain := range chains {
 		if chain.isClaimed() {
@@ -59,12 +61,16 @@ func (m *MultiChain) Send(values ...any) error {
 	}
 
 	for _, v := range values {
-		m.chanIn <- v
+		m.chanIn <- m.envelop(v)
+		m.countSent(1)
 	}
 	return nil
 }
 
 func (m *MultiChain) disperseInput(input any) {
+	if t, ok := input.(*transported); ok {
+		input = t.shared(len(m.chanIns))
+	}
 	for _, chanIn := range m.chanIns {
 		chanIn <- input
 	}
@@ -85,14 +91,6 @@ func (m *MultiChain) Close() {
 func (m *MultiChain) Wait() {
 	m.startIfUnstarted()
 
-	m.errLock.Lock()
-	err := m.getError()
-	m.errLock.Unlock()
-
-	if err != nil {
-		return
-	}
-
 	if len(m.outputters) == 0 {
 		// caller has called Wait() with no outputters.
 		// c.wgOut.Wait() will deadlock if we do not empty this channel.
@@ -100,12 +98,14 @@ func (m *MultiChain) Wait() {
 	}
 
 	m.wgOut.Wait()
+	m.waitEvents()
+	m.writeManifest()
 }
 
 func (m *MultiChain) startIfUnstarted() {
-	if !m.hasStarted() {
+	m.startOnce.Do(func() {
 		m.start(m.chanIn, m.handleError, m.strictness)
-	}
+	})
 	m.setStarted()
 }
 
@@ -120,27 +120,44 @@ func (m *MultiChain) Error() error {
 }
 
 func (m *MultiChain) start(prevChan chan any, errHandler func(error), strictness Strictness) {
+	m.startedAt = time.Now()
 	m.initializeLogger()
+	m.startEvents()
 
 	if err := m.resetParams(); err != nil {
 		errHandler(err)
+		m.finishEvents()
+		m.Base.Close() // nothing will be sent, so receivers must not block
 		return
 	}
 
+	if err := m.preflight(); err != nil {
+		errHandler(err)
+		m.finishEvents()
+		m.Base.Close() // nothing will be sent, so receivers must not block
+		return
+	}
+
+	m.snapshotManifest()
 	for _, outputter := range m.outputters {
 		if err := m.startOutputter(outputter); err != nil {
 			errHandler(err)
 		}
 	}
 
+	m.emitChainStarted()
+	m.startSpan()
+	m.startProgress()
 	go m.startDisperser(prevChan)
 
+	outputs := []chan any{}
 	for i, child := range m.children() {
-		m.startChild(child, m.chanIns[i], errHandler, strictness)
+		output, _ := m.startChild(child, m.chanIns[i], errHandler, strictness)
+		outputs = append(outputs, output)
 	}
 
 	m.wgOut.Add(1)
-	go m.collectOutput()
+	go m.collectOutput(outputs, errHandler, strictness)
 }
 
 func (m *MultiChain) startOutputter(outputter Outputter) error {
@@ -148,6 +165,8 @@ func (m *MultiChain) startOutputter(outputter Outputter) error {
 	if err != nil {
 		return err
 	}
+	m.snapshotTargets(outputter)
+	m.inheritContext(outputter)
 
 	err = outputter.Initialize()
 	if err != nil {
@@ -172,24 +191,39 @@ func (m *MultiChain) startDisperser(prevChan chan any) {
 func (m *MultiChain) startChild(child Link, prevChan chan any, errHandler func(error), strictness Strictness) (chan any, error) {
 	if err := m.setArgs(child); err != nil {
 		errHandler(err)
-		return nil, err
+		return skipChild(prevChan), err
 	}
+	m.snapshotTargets(child)
 
+	if err := m.injectCredentials(child); err != nil {
+		errHandler(err)
+		return skipChild(prevChan), err
+	}
+
+	m.inheritContext(child)
+	m.traceChild(child)
+	m.instrumentChild(child)
+	m.forwardEvents(child)
 	go child.start(prevChan, errHandler, strictness)
 	return child.channel(), nil
 }
 
-func (m *MultiChain) collectOutput() {
+func (m *MultiChain) collectOutput(outputs []chan any, errHandler func(error), strictness Strictness) {
 	defer func() {
 		m.flushOutputItems()
-		close(m.channel())
 		m.closeOutputters()
+		m.endSpan()
+		m.stopProgress()
+		m.finishEvents() // before closing the channel, so the parent chain is still listening
+		close(m.channel())
 		m.wgOut.Done()
 	}()
 
-	for _, child := range m.children() {
-		for v := range child.channel() {
-			m.output(v)
+	for _, output := range outputs {
+		for v := range output {
+			if err := m.output(v, strictness); err != nil {
+				errHandler(err)
+			}
 		}
 	}
 }
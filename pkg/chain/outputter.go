@@ -4,10 +4,56 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"reflect"
+	"sync/atomic"
 
 	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
 )
 
+// OutputPolicy decides what a chain does when one of its outputters fails to output an item.
+type OutputPolicy int
+
+const (
+	OutputDefault OutputPolicy = iota // Warn, or kill the chain if it is Strict
+	OutputIgnore                      // Discard the error
+	OutputWarn                        // Log the error and continue
+	OutputFail                        // Kill the chain
+)
+
+func (p OutputPolicy) String() string {
+	switch p {
+	case OutputDefault:
+		return "Default"
+	case OutputIgnore:
+		return "Ignore"
+	case OutputWarn:
+		return "Warn"
+	case OutputFail:
+		return "Fail"
+	default:
+		return fmt.Sprintf("OutputPolicy(%d)", p)
+	}
+}
+
+// resolve turns OutputDefault into the policy for the chain's strictness: outputter errors only
+// warn, unless the chain is Strict.
+func (p OutputPolicy) resolve(strictness Strictness) OutputPolicy {
+	if p != OutputDefault {
+		return p
+	}
+	if strictness == Strict {
+		return OutputFail
+	}
+	return OutputWarn
+}
+
+// TypeFilter is implemented by outputters that only handle items of certain types. Items of
+// other types are skipped without calling Output. Types may be interfaces, which match any
+// item implementing them; a struct type also matches pointers to it, and vice versa.
+type TypeFilter interface {
+	AcceptedTypes() []reflect.Type
+}
+
 type Outputter interface {
 	outputterMethods
 }
@@ -21,15 +67,24 @@ type outputterMethods interface {
 	Name() string
 	Complete() error
 	Initialize() error
+	WithAcceptedTypes(types ...reflect.Type) Outputter
+	WithErrorPolicy(policy OutputPolicy) Outputter
+	Skipped() int
+	accepts(item any) bool
+	skip()
+	errorPolicy() OutputPolicy
 }
 
 type BaseOutputter struct {
 	*cfg.ContextHolder
 	*cfg.ParamHolder
 	*cfg.Logger
-	name  string
-	err   error
-	super Outputter
+	name     string
+	err      error
+	super    Outputter
+	accepted []reflect.Type
+	policy   OutputPolicy
+	skipped  atomic.Int64
 }
 
 func NewBaseOutputter(outputter Outputter, configs ...cfg.Config) *BaseOutputter {
@@ -97,6 +152,69 @@ func (o *BaseOutputter) Complete() error {
 	return nil
 }
 
+// WithAcceptedTypes limits the outputter to items of the given types, overriding the types it
+// declares as a TypeFilter.
+func (b *BaseOutputter) WithAcceptedTypes(types ...reflect.Type) Outputter {
+	b.accepted = types
+	return b.super
+}
+
+// WithErrorPolicy sets what the chain does when this outputter fails to output an item.
+func (b *BaseOutputter) WithErrorPolicy(policy OutputPolicy) Outputter {
+	b.policy = policy
+	return b.super
+}
+
+// Skipped returns the number of items the outputter skipped because of their type.
+func (b *BaseOutputter) Skipped() int {
+	return int(b.skipped.Load())
+}
+
+func (b *BaseOutputter) accepts(item any) bool {
+	accepted := b.accepted
+	if len(accepted) == 0 {
+		if filter, ok := b.super.(TypeFilter); ok {
+			accepted = filter.AcceptedTypes()
+		}
+	}
+	if len(accepted) == 0 {
+		return true
+	}
+
+	itemType := reflect.TypeOf(item)
+	if itemType == nil {
+		return false
+	}
+
+	for _, acceptedType := range accepted {
+		if typeAccepts(acceptedType, itemType) {
+			return true
+		}
+	}
+	return false
+}
+
+func typeAccepts(accepted, item reflect.Type) bool {
+	if item == accepted || reflect.PointerTo(item) == accepted {
+		return true
+	}
+	if item.Kind() == reflect.Ptr && item.Elem() == accepted {
+		return true
+	}
+	if accepted.Kind() == reflect.Interface {
+		return item.Implements(accepted) || reflect.PointerTo(item).Implements(accepted)
+	}
+	return false
+}
+
+func (b *BaseOutputter) skip() {
+	b.skipped.Add(1)
+}
+
+func (b *BaseOutputter) errorPolicy() OutputPolicy {
+	return b.policy
+}
+
 func (b *BaseOutputter) SetLogger(logger *slog.Logger) {
 	b.Logger.SetLogger(logger)
 }
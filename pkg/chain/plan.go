@@ -0,0 +1,379 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/util"
+)
+
+// OutputTyper is implemented by links that declare the types they send.
+type OutputTyper interface {
+	OutputTypes() []reflect.Type
+}
+
+// FileTargeter is implemented by links and outputters that write files or directories. It
+// must report its targets from args alone, without having been initialized.
+type FileTargeter interface {
+	TargetFiles() []string
+}
+
+// BinaryUser is implemented by links that execute external binaries.
+type BinaryUser interface {
+	Binaries() []string
+}
+
+const UnknownInputCount = -1
+
+const maxPlannedArgWidth = 50
+
+// Plan describes what a chain will do when run, without running it.
+type Plan struct {
+	Name        string
+	Inputs      int
+	Args        []PlannedArg
+	Links       []PlannedLink
+	Outputters  []PlannedOutputter
+	Binaries    []PlannedBinary
+	Permissions map[cfg.Platform][]string
+	Errors      []string
+}
+
+type PlannedArg struct {
+	Name   string
+	Type   string
+	Value  any
+	Source cfg.ArgSource
+}
+
+type PlannedLink struct {
+	Path        string
+	InputType   string
+	OutputTypes []string
+	Args        []PlannedArg
+	Files       []string
+	Binaries    []string
+	Permissions []string
+}
+
+type PlannedOutputter struct {
+	Name  string
+	Args  []PlannedArg
+	Files []string
+}
+
+type PlannedBinary struct {
+	Name  string
+	Found bool
+}
+
+// Plan resolves args, validates params and describes the chain's links and outputters. No link
+// or outputter is initialized and no item is processed.
+func (c *BaseChain) Plan() (*Plan, error) {
+	plan := &Plan{
+		Name:        c.Name(),
+		Inputs:      UnknownInputCount,
+		Permissions: c.PermissionsMap(),
+	}
+
+	if err := c.resetParams(); err != nil {
+		return nil, err
+	}
+
+	plan.Args = plannedArgs(c.Base, c.Params(), nil)
+
+	errs := []error{}
+	for _, link := range c.children() {
+		errs = append(errs, c.planLink(plan, link)...)
+	}
+
+	for _, outputter := range c.outputters {
+		errs = append(errs, c.planOutputter(plan, outputter)...)
+	}
+
+	binaries := []string{}
+	for _, link := range plan.Links {
+		binaries = append(binaries, link.Binaries...)
+	}
+	slices.Sort(binaries)
+	for _, binary := range slices.Compact(binaries) {
+		plan.Binaries = append(plan.Binaries, PlannedBinary{Name: binary, Found: util.CheckBinaryExists(binary)})
+	}
+
+	for _, err := range errs {
+		plan.Errors = append(plan.Errors, err.Error())
+	}
+
+	return plan, errors.Join(errs...)
+}
+
+func (c *BaseChain) planLink(plan *Plan, link Link) []error {
+	before := argSources(link)
+	if err := c.setArgs(link); err != nil {
+		return []error{fmt.Errorf("link %s: %w", link.Name(), err)}
+	}
+
+	if nested, ok := asBaseChain(link); ok {
+		if err := nested.resetParams(); err != nil {
+			return []error{err}
+		}
+
+		errs := []error{}
+		for _, child := range nested.children() {
+			errs = append(errs, nested.planLink(plan, child)...)
+		}
+		return errs
+	}
+
+	planned := PlannedLink{
+		Path:        linkPath(link),
+		InputType:   inputTypeOf(link),
+		Args:        plannedArgs(link, link.Params(), func(name string) cfg.ArgSource { return c.argSourceFor(name, before) }),
+		Permissions: permissionStrings(link.Permissions()),
+	}
+
+	if typer, ok := link.(OutputTyper); ok {
+		for _, t := range typer.OutputTypes() {
+			planned.OutputTypes = append(planned.OutputTypes, t.String())
+		}
+	}
+
+	if targeter, ok := link.(FileTargeter); ok {
+		planned.Files = targeter.TargetFiles()
+	}
+
+	if user, ok := link.(BinaryUser); ok {
+		planned.Binaries = user.Binaries()
+	}
+
+	plan.Links = append(plan.Links, planned)
+
+	if err := validate(link); err != nil {
+		return []error{fmt.Errorf("link %s failed to validate params: %w", planned.Path, err)}
+	}
+	return nil
+}
+
+func (c *BaseChain) planOutputter(plan *Plan, outputter Outputter) []error {
+	before := argSources(outputter)
+	if err := c.setOutputterArgs(outputter); err != nil {
+		return []error{fmt.Errorf("outputter %s: %w", outputter.Name(), err)}
+	}
+
+	planned := PlannedOutputter{
+		Name: outputter.Name(),
+		Args: plannedArgs(outputter, outputter.Params(), func(name string) cfg.ArgSource { return c.argSourceFor(name, before) }),
+	}
+
+	if targeter, ok := outputter.(FileTargeter); ok {
+		planned.Files = targeter.TargetFiles()
+	}
+
+	plan.Outputters = append(plan.Outputters, planned)
+	return nil
+}
+
+// argSourceFor reports the source of an arg that was set on a link or outputter. Args the
+// link already had keep their own source; args copied from the chain report the chain's.
+func (c *BaseChain) argSourceFor(name string, before map[string]cfg.ArgSource) cfg.ArgSource {
+	if source, ok := before[name]; ok && source != cfg.ArgSourceDefault && source != cfg.ArgSourceUnset {
+		return source
+	}
+
+	if c.Base.WasSet(name) {
+		return c.Base.ArgSource(name)
+	}
+
+	if source, ok := before[name]; ok {
+		return source
+	}
+	return cfg.ArgSourceUnset
+}
+
+type argSourcer interface {
+	ArgSource(name string) cfg.ArgSource
+}
+
+func argSources(paramable cfg.Paramable) map[string]cfg.ArgSource {
+	sources := make(map[string]cfg.ArgSource)
+	sourcer, ok := paramable.(argSourcer)
+	if !ok {
+		return sources
+	}
+
+	for _, param := range paramable.Params() {
+		sources[param.Name()] = sourcer.ArgSource(param.Name())
+	}
+	return sources
+}
+
+// plannedArgs describes the args of params, redacting the values of sensitive params.
+func plannedArgs(holder cfg.Paramable, params []cfg.Param, sourceFn func(string) cfg.ArgSource) []PlannedArg {
+	if sourceFn == nil {
+		sourcer, ok := holder.(argSourcer)
+		sourceFn = func(name string) cfg.ArgSource {
+			if !ok {
+				return cfg.ArgSourceUnset
+			}
+			return sourcer.ArgSource(name)
+		}
+	}
+
+	args := []PlannedArg{}
+	for _, param := range params {
+		value := holder.Arg(param.Name())
+		if cfg.IsSensitive(param) && value != nil && fmt.Sprintf("%v", value) != "" {
+			value = cfg.RedactedValue
+		}
+
+		args = append(args, PlannedArg{
+			Name:   param.Name(),
+			Type:   param.Type(),
+			Value:  value,
+			Source: sourceFn(param.Name()),
+		})
+	}
+
+	sort.Slice(args, func(i, j int) bool {
+		return args[i].Name < args[j].Name
+	})
+	return args
+}
+
+func validate(paramable cfg.Paramable) error {
+	validator, ok := paramable.(interface{ Validate() error })
+	if !ok {
+		return nil
+	}
+	return validator.Validate()
+}
+
+func asBaseChain(link Link) (*BaseChain, bool) {
+	switch c := link.(type) {
+	case *BaseChain:
+		return c, true
+	case *MultiChain:
+		return c.BaseChain, true
+	default:
+		return nil, false
+	}
+}
+
+func linkPath(link Link) string {
+	if pather, ok := link.(interface{ LinkPath() string }); ok {
+		return pather.LinkPath()
+	}
+	return link.Name()
+}
+
+func inputTypeOf(link Link) string {
+	method := reflect.ValueOf(link).MethodByName("Process")
+	if !method.IsValid() || method.Type().NumIn() != 1 {
+		return "unknown"
+	}
+	return method.Type().In(0).String()
+}
+
+func permissionStrings(permissions []cfg.Permission) []string {
+	strs := []string{}
+	for _, permission := range permissions {
+		strs = append(strs, permission.String())
+	}
+	return strs
+}
+
+// String renders the plan for humans, e.g. for a --dry-run flag.
+func (p *Plan) String() string {
+	sb := &strings.Builder{}
+
+	fmt.Fprintf(sb, "Plan for %s\n", p.Name)
+	if p.Inputs == UnknownInputCount {
+		fmt.Fprintf(sb, "Inputs: unknown\n")
+	} else {
+		fmt.Fprintf(sb, "Inputs: %d\n", p.Inputs)
+	}
+
+	fmt.Fprintf(sb, "\nArgs:\n")
+	writePlannedArgs(sb, p.Args, "  ")
+
+	fmt.Fprintf(sb, "\nLinks:\n")
+	for i, link := range p.Links {
+		outputTypes := "unknown"
+		if len(link.OutputTypes) > 0 {
+			outputTypes = strings.Join(link.OutputTypes, ", ")
+		}
+		fmt.Fprintf(sb, "  %d. %s (%s -> %s)\n", i+1, link.Path, link.InputType, outputTypes)
+		writePlannedArgs(sb, link.Args, "       ")
+		for _, file := range link.Files {
+			fmt.Fprintf(sb, "       writes %s\n", file)
+		}
+		for _, permission := range link.Permissions {
+			fmt.Fprintf(sb, "       requires %s\n", permission)
+		}
+	}
+
+	fmt.Fprintf(sb, "\nOutputters:\n")
+	for _, outputter := range p.Outputters {
+		fmt.Fprintf(sb, "  %s\n", outputter.Name)
+		for _, file := range outputter.Files {
+			fmt.Fprintf(sb, "    writes %s\n", file)
+		}
+	}
+
+	if len(p.Binaries) > 0 {
+		fmt.Fprintf(sb, "\nBinaries:\n")
+		for _, binary := range p.Binaries {
+			status := "found"
+			if !binary.Found {
+				status = "NOT FOUND"
+			}
+			fmt.Fprintf(sb, "  %s (%s)\n", binary.Name, status)
+		}
+	}
+
+	if len(p.Permissions) > 0 {
+		fmt.Fprintf(sb, "\nPermissions:\n")
+		platforms := []string{}
+		for platform := range p.Permissions {
+			platforms = append(platforms, string(platform))
+		}
+		sort.Strings(platforms)
+		for _, platform := range platforms {
+			fmt.Fprintf(sb, "  %s: %s\n", platform, strings.Join(p.Permissions[cfg.Platform(platform)], ", "))
+		}
+	}
+
+	if len(p.Errors) > 0 {
+		fmt.Fprintf(sb, "\nErrors:\n")
+		for _, err := range p.Errors {
+			fmt.Fprintf(sb, "  %s\n", err)
+		}
+	}
+
+	return sb.String()
+}
+
+func writePlannedArgs(sb *strings.Builder, args []PlannedArg, indent string) {
+	for _, arg := range args {
+		fmt.Fprintf(sb, "%s%s (%s) = %s [%s]\n", indent, arg.Name, arg.Type, truncateArg(arg.Value), arg.Source)
+	}
+}
+
+// truncateArg shortens args longer than maxPlannedArgWidth characters, cutting between runes so
+// multibyte characters aren't split.
+func truncateArg(value any) string {
+	str := fmt.Sprintf("%v", value)
+	if reflect.ValueOf(value).Kind() == reflect.Func {
+		str = "<func>"
+	}
+	if utf8.RuneCountInString(str) > maxPlannedArgWidth {
+		return string([]rune(str)[:maxPlannedArgWidth]) + " ...(truncated)"
+	}
+	return str
+}
@@ -0,0 +1,233 @@
+package chain_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/links"
+	"github.com/praetorian-inc/janus-framework/pkg/output"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPlanTestModule(w *bytes.Buffer, outfile string) *chain.Module {
+	return chain.NewModule(
+		cfg.NewMetadata(
+			"plan-test",
+			"plan test",
+		).WithChainInputParam("strings"),
+	).WithLinks(
+		basics.NewStrLink,
+		func(configs ...cfg.Config) chain.Link {
+			return links.FromWrapper(func(s string) int { return len(s) }, configs...)
+		},
+		func(configs ...cfg.Config) chain.Link {
+			return basics.NewPermissionsLink(configs...).WithPermissions(cfg.NewPermission(cfg.AWS, "s3:GetObject"))
+		},
+	).WithConfigs(
+		cfg.WithArg("writer", w),
+		cfg.WithArg("jsonoutfile", outfile),
+	).WithInputParam(
+		cfg.NewParam[[]string]("strings", "strings to process"),
+	).WithOutputters(
+		output.NewWriterOutputter,
+		output.NewJSONOutputter,
+	)
+}
+
+func TestModule_Plan(t *testing.T) {
+	w := &bytes.Buffer{}
+	outfile := filepath.Join(t.TempDir(), "out.json")
+	module := newPlanTestModule(w, outfile)
+
+	plan, err := module.Plan(cfg.WithCLIArgs([]string{"-strings", "a,b,c"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, "plan-test", plan.Name)
+	assert.Equal(t, 3, plan.Inputs)
+	assert.Equal(t, []string{"s3:GetObject"}, plan.Permissions[cfg.AWS])
+
+	args := map[string]chain.PlannedArg{}
+	for _, arg := range plan.Args {
+		args[arg.Name] = arg
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, args["strings"].Value)
+	assert.Equal(t, cfg.ArgSourceCLI, args["strings"].Source)
+	assert.Equal(t, cfg.ArgSourceConfig, args["jsonoutfile"].Source)
+	assert.Equal(t, cfg.ArgSourceDefault, args["indent"].Source)
+
+	require.Len(t, plan.Links, 3)
+	assert.Equal(t, "string", plan.Links[0].InputType)
+	assert.Equal(t, "string", plan.Links[1].InputType)
+	assert.Equal(t, []string{"int"}, plan.Links[1].OutputTypes)
+	assert.Equal(t, "interface {}", plan.Links[2].InputType)
+	assert.Equal(t, []string{"AWS:s3:GetObject"}, plan.Links[2].Permissions)
+
+	require.Len(t, plan.Outputters, 2)
+	assert.Equal(t, []string{outfile}, plan.Outputters[1].Files)
+
+	assert.NoFileExists(t, outfile, "planning must not initialize outputters")
+	assert.Empty(t, w.String(), "planning must not process inputs")
+}
+
+func TestModule_Plan_ValidationError(t *testing.T) {
+	module := chain.NewModule(
+		cfg.NewMetadata(
+			"plan-test",
+			"plan test",
+		),
+	).WithLinks(
+		basics.NewParamsLink,
+	).WithOutputters(
+		output.NewWriterOutputter,
+	).WithAutoRun()
+
+	plan, err := module.Plan()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `parameter "required" is required`)
+	require.NotNil(t, plan)
+	assert.Equal(t, 1, plan.Inputs)
+	assert.Len(t, plan.Errors, 1)
+}
+
+func TestModule_WithDryRun(t *testing.T) {
+	w := &bytes.Buffer{}
+	outfile := filepath.Join(t.TempDir(), "out.json")
+	planWriter := &bytes.Buffer{}
+
+	module := newPlanTestModule(w, outfile).WithDryRun(planWriter)
+
+	err := module.Run(cfg.WithCLIArgs([]string{"-strings", "a,b"}))
+	require.NoError(t, err)
+
+	assert.Contains(t, planWriter.String(), "Plan for plan-test")
+	assert.Contains(t, planWriter.String(), "Inputs: 2")
+	assert.Contains(t, planWriter.String(), "strings ([]string) = [a b] [cli]")
+	assert.Contains(t, planWriter.String(), "writes "+outfile)
+	assert.Contains(t, planWriter.String(), "AWS: s3:GetObject")
+
+	_, statErr := os.Stat(outfile)
+	assert.True(t, os.IsNotExist(statErr))
+	assert.Empty(t, w.String())
+}
+
+func TestModule_DryRunParam(t *testing.T) {
+	w := &bytes.Buffer{}
+	outfile := filepath.Join(t.TempDir(), "out.json")
+
+	stdout := os.Stdout
+	r, pipe, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = pipe
+	defer func() { os.Stdout = stdout }()
+
+	err = newPlanTestModule(w, outfile).Run(cfg.WithCLIArgs([]string{"-strings", "a,b", "-dry-run"}))
+	require.NoError(t, pipe.Close())
+	os.Stdout = stdout
+	require.NoError(t, err)
+
+	printed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(printed), "Plan for plan-test")
+	assert.Contains(t, string(printed), "Inputs: 2")
+
+	assert.NoFileExists(t, outfile)
+	assert.Empty(t, w.String())
+}
+
+func TestModule_DryRunParam_False(t *testing.T) {
+	w := &bytes.Buffer{}
+	outfile := filepath.Join(t.TempDir(), "out.json")
+
+	err := newPlanTestModule(w, outfile).Run(cfg.WithCLIArgs([]string{"-strings", "a,b", "-dry-run", "false"}))
+	require.NoError(t, err)
+	assert.FileExists(t, outfile)
+}
+
+func TestModule_DryRunParam_Invalid(t *testing.T) {
+	w := &bytes.Buffer{}
+	outfile := filepath.Join(t.TempDir(), "out.json")
+
+	err := newPlanTestModule(w, outfile).Run(cfg.WithCLIArgs([]string{"-strings", "a,b", "-dry-run", "maybe"}))
+	assert.ErrorContains(t, err, `invalid dry-run value "maybe"`)
+	assert.NoFileExists(t, outfile)
+}
+
+type secretsLink struct {
+	*chain.Base
+}
+
+func newSecretsLink(configs ...cfg.Config) chain.Link {
+	s := &secretsLink{}
+	s.Base = chain.NewBase(s, configs...)
+	return s
+}
+
+func (s *secretsLink) Params() []cfg.Param {
+	return []cfg.Param{
+		cfg.NewParam[string]("api-token", "an API token"),
+		cfg.NewParam[string]("vault", "a vault secret").AsSensitive(),
+		cfg.NewParam[string]("region", "a region"),
+	}
+}
+
+func (s *secretsLink) Process(input string) error {
+	return s.Send(input)
+}
+
+func TestModule_Plan_RedactsSensitiveArgs(t *testing.T) {
+	planWriter := &bytes.Buffer{}
+
+	module := chain.NewModule(
+		cfg.NewMetadata(
+			"plan-test",
+			"plan test",
+		),
+	).WithLinks(
+		newSecretsLink,
+	).WithOutputters(
+		output.NewWriterOutputter,
+	).WithAutoRun().WithDryRun(planWriter)
+
+	err := module.Run(cfg.WithCLIArgs([]string{
+		"-api-token", "tok-12345",
+		"-vault", "hunter2",
+		"-region", "us-east-1",
+	}))
+	require.NoError(t, err)
+
+	assert.NotContains(t, planWriter.String(), "tok-12345")
+	assert.NotContains(t, planWriter.String(), "hunter2")
+	assert.Contains(t, planWriter.String(), "api-token (string) = "+cfg.RedactedValue)
+	assert.Contains(t, planWriter.String(), "vault (string) = "+cfg.RedactedValue)
+	assert.Contains(t, planWriter.String(), "region (string) = us-east-1")
+}
+
+func TestModule_Plan_TruncatesArgsByRune(t *testing.T) {
+	planWriter := &bytes.Buffer{}
+
+	module := chain.NewModule(
+		cfg.NewMetadata(
+			"plan-test",
+			"plan test",
+		),
+	).WithLinks(
+		newSecretsLink,
+	).WithOutputters(
+		output.NewWriterOutputter,
+	).WithAutoRun().WithDryRun(planWriter)
+
+	err := module.Run(cfg.WithArg("region", "a"+strings.Repeat("日本", 30)))
+	require.NoError(t, err)
+
+	assert.True(t, utf8.Valid(planWriter.Bytes()), "truncation should not split multibyte characters")
+	assert.Contains(t, planWriter.String(), "region (string) = a"+strings.Repeat("日本", 24)+"日 ...(truncated)")
+}
@@ -0,0 +1,122 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+)
+
+// MissingPermission is a permission required by a link that the available credentials do
+// not grant.
+type MissingPermission struct {
+	Link       string
+	Permission cfg.Permission
+}
+
+func (m MissingPermission) String() string {
+	return fmt.Sprintf("%s requires %s", m.Link, m.Permission)
+}
+
+type PermissionError struct {
+	Missing []MissingPermission
+}
+
+func (e *PermissionError) Error() string {
+	missing := []string{}
+	for _, m := range e.Missing {
+		missing = append(missing, m.String())
+	}
+	return fmt.Sprintf("missing %d permission(s): %s", len(e.Missing), strings.Join(missing, "; "))
+}
+
+// WithPermissionVerifiers makes the chain verify, before it processes its first input, that
+// the permissions its links declare are granted. Platforms without a verifier are not checked.
+func (c *BaseChain) WithPermissionVerifiers(verifiers ...cfg.PermissionVerifier) Chain {
+	c.verifiers = verifiers
+	return c.super
+}
+
+// VerifyPermissions checks the permissions of every link against the chain's verifiers. A
+// non-nil error is a *PermissionError if permissions are missing, or the verifier's error if
+// one could not complete.
+func (c *BaseChain) VerifyPermissions() error {
+	required := make(map[cfg.Platform][]string)
+	byLink := []MissingPermission{}
+	for _, link := range leafLinks(c) {
+		for _, permission := range link.Permissions() {
+			required[permission.Platform] = append(required[permission.Platform], permission.Permission)
+			byLink = append(byLink, MissingPermission{Link: linkPath(link), Permission: permission})
+		}
+	}
+
+	missing := make(map[cfg.Permission]bool)
+	errs := []error{}
+	for _, verifier := range c.verifiers {
+		permissions := required[verifier.Platform()]
+		if len(permissions) == 0 {
+			continue
+		}
+
+		slices.Sort(permissions)
+		denied, err := verifier.Verify(c.Context(), slices.Compact(permissions))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to verify %s permissions: %w", verifier.Platform(), err))
+			continue
+		}
+
+		for _, permission := range denied {
+			missing[cfg.NewPermission(verifier.Platform(), permission)] = true
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	permErr := &PermissionError{}
+	for _, m := range byLink {
+		if missing[m.Permission] {
+			permErr.Missing = append(permErr.Missing, m)
+		}
+	}
+
+	if len(permErr.Missing) > 0 {
+		return permErr
+	}
+	return nil
+}
+
+// preflight verifies permissions before the chain starts. Under Lax strictness failures are
+// logged and the chain starts anyway; otherwise the chain refuses to start.
+func (c *BaseChain) preflight() error {
+	if len(c.verifiers) == 0 {
+		return nil
+	}
+
+	err := c.VerifyPermissions()
+	if err == nil {
+		return nil
+	}
+
+	if c.strictness == Lax {
+		c.Logger.Warn("permission preflight failed, continuing", "error", err)
+		return nil
+	}
+	return fmt.Errorf("permission preflight failed: %w", err)
+}
+
+// leafLinks returns the links of a chain in order, descending into nested chains.
+func leafLinks(c *BaseChain) []Link {
+	links := []Link{}
+	for _, link := range c.children() {
+		if nested, ok := asBaseChain(link); ok {
+			links = append(links, leafLinks(nested)...)
+			continue
+		}
+		links = append(links, link)
+	}
+	return links
+}
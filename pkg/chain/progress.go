@@ -0,0 +1,261 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProgressEvent is a snapshot of a chain's progress.
+type ProgressEvent struct {
+	Chain   string    `json:"chain"`
+	Started time.Time `json:"started"`
+	Time    time.Time `json:"time"`
+	// Total is the number of inputs the chain expects, or UnknownInputCount.
+	Total int `json:"total"`
+	// Sent is the number of inputs sent to the chain so far.
+	Sent  int64          `json:"sent"`
+	Links []LinkProgress `json:"links"`
+	// Done is set on the last event, sent once the chain has finished.
+	Done bool `json:"done"`
+}
+
+// LinkProgress counts the items a link has finished processing.
+type LinkProgress struct {
+	Link      string `json:"link"`
+	Completed int64  `json:"completed"`
+	Failed    int64  `json:"failed"`
+}
+
+// Elapsed returns how long the chain had been running at the time of the event.
+func (e ProgressEvent) Elapsed() time.Duration {
+	return e.Time.Sub(e.Started)
+}
+
+// Completed returns the number of inputs the chain's first link has finished processing.
+func (e ProgressEvent) Completed() int64 {
+	if len(e.Links) == 0 {
+		return 0
+	}
+	return e.Links[0].Completed
+}
+
+// Fraction returns the share of the expected inputs completed, between 0 and 1. It returns
+// false if the number of inputs is unknown.
+func (e ProgressEvent) Fraction() (float64, bool) {
+	if e.Total <= 0 {
+		return 0, false
+	}
+	return min(float64(e.Completed())/float64(e.Total), 1), true
+}
+
+// ProgressReporter receives a chain's progress events. Events are reported one at a time, in
+// order, from a goroutine of the chain's own.
+type ProgressReporter interface {
+	ReportProgress(ProgressEvent)
+}
+
+// ProgressReporterFunc adapts a function to a ProgressReporter.
+type ProgressReporterFunc func(ProgressEvent)
+
+func (f ProgressReporterFunc) ReportProgress(event ProgressEvent) {
+	f(event)
+}
+
+// WithProgress makes the chain report its progress to reporters: once it starts, every
+// interval while it runs, and once it finishes. Each event counts the items every link has
+// completed, and the inputs sent out of the total given to WithExpectedInputs.
+func (c *BaseChain) WithProgress(interval time.Duration, reporters ...ProgressReporter) Chain {
+	c.progress = &progressTracker{interval: interval, reporters: reporters}
+	return c.super
+}
+
+// WithExpectedInputs tells the chain how many inputs it will be sent, so its progress events
+// can report how far along it is. Modules set it from their CountableInputSource.
+func (c *BaseChain) WithExpectedInputs(count int) Chain {
+	c.expectedInputs.Store(int64(count))
+	return c.super
+}
+
+type progressTracker struct {
+	interval  time.Duration
+	reporters []ProgressReporter
+	sent      atomic.Int64
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// itemCounts returns the number of items the link has finished processing, and how many of
+// them failed.
+func (b *Base) itemCounts() (completed, failed int64) {
+	return b.completed.Load(), b.failed.Load()
+}
+
+func (b *Base) countItem(err error) {
+	b.completed.Add(1)
+	if err != nil {
+		b.failed.Add(1)
+	}
+}
+
+func (c *BaseChain) countSent(n int) {
+	if c.progress != nil {
+		c.progress.sent.Add(int64(n))
+	}
+}
+
+// startProgress reports the chain's progress until stopProgress is called.
+func (c *BaseChain) startProgress() {
+	p := c.progress
+	if p == nil || len(p.reporters) == 0 {
+		return
+	}
+
+	p.stop = make(chan struct{})
+	p.stopped = make(chan struct{})
+	p.report(c.progressEvent(false))
+
+	go func() {
+		defer close(p.stopped)
+		var tick <-chan time.Time
+		if p.interval > 0 {
+			ticker := time.NewTicker(p.interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-tick:
+				p.report(c.progressEvent(false))
+			case <-p.stop:
+				p.report(c.progressEvent(true))
+				return
+			}
+		}
+	}()
+}
+
+// stopProgress reports the chain's final progress, and waits for the reporters to receive it.
+func (c *BaseChain) stopProgress() {
+	p := c.progress
+	if p == nil || p.stop == nil {
+		return
+	}
+	close(p.stop)
+	<-p.stopped
+}
+
+func (p *progressTracker) report(event ProgressEvent) {
+	for _, reporter := range p.reporters {
+		reporter.ReportProgress(event)
+	}
+}
+
+func (c *BaseChain) progressEvent(done bool) ProgressEvent {
+	event := ProgressEvent{
+		Chain:   c.Name(),
+		Started: c.startedAt,
+		Time:    time.Now(),
+		Total:   int(c.expectedInputs.Load()),
+		Sent:    c.progress.sent.Load(),
+		Links:   []LinkProgress{},
+		Done:    done,
+	}
+
+	for _, link := range leafLinks(c) {
+		counter, ok := link.(interface{ itemCounts() (int64, int64) })
+		if !ok {
+			continue
+		}
+		completed, failed := counter.itemCounts()
+		event.Links = append(event.Links, LinkProgress{Link: linkPath(link), Completed: completed, Failed: failed})
+	}
+	return event
+}
+
+// ProgressBar renders progress events as a progress bar. On a terminal, the bar is redrawn in
+// place; otherwise each event is written on a line of its own. It writes to its own writer,
+// under its own lock, so it never waits on the chain's loggers.
+type ProgressBar struct {
+	mu    sync.Mutex
+	w     io.Writer
+	tty   bool
+	width int
+}
+
+// NewProgressBar returns a ProgressBar writing to w, or to stderr if w is nil.
+func NewProgressBar(w io.Writer) *ProgressBar {
+	if w == nil {
+		w = os.Stderr
+	}
+	return &ProgressBar{w: w, tty: isTerminal(w), width: 30}
+}
+
+func (p *ProgressBar) ReportProgress(event ProgressEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	line := p.render(event)
+	switch {
+	case !p.tty:
+		fmt.Fprintln(p.w, line)
+	case event.Done:
+		fmt.Fprintf(p.w, "\r\x1b[K%s\n", line)
+	default:
+		fmt.Fprintf(p.w, "\r\x1b[K%s", line)
+	}
+}
+
+func (p *ProgressBar) render(event ProgressEvent) string {
+	var b strings.Builder
+	b.WriteString(event.Chain)
+
+	if fraction, ok := event.Fraction(); ok {
+		filled := int(fraction * float64(p.width))
+		fmt.Fprintf(&b, " [%s%s] %d/%d (%.0f%%)", strings.Repeat("#", filled), strings.Repeat(".", p.width-filled),
+			event.Completed(), event.Total, fraction*100)
+	} else {
+		fmt.Fprintf(&b, " %d inputs", event.Completed())
+	}
+	fmt.Fprintf(&b, " %s", event.Elapsed().Round(time.Second))
+
+	for _, link := range event.Links {
+		fmt.Fprintf(&b, " | %s %d", path.Base(link.Link), link.Completed)
+		if link.Failed > 0 {
+			fmt.Fprintf(&b, " (%d failed)", link.Failed)
+		}
+	}
+	return b.String()
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ProgressStream writes progress events as JSON lines, for UIs and other programs to follow.
+type ProgressStream struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func NewProgressStream(w io.Writer) *ProgressStream {
+	return &ProgressStream{encoder: json.NewEncoder(w)}
+}
+
+func (s *ProgressStream) ReportProgress(event ProgressEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.encoder.Encode(event)
+}
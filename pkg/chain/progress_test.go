@@ -0,0 +1,145 @@
+package chain_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/output"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recordProgress(events *[]chain.ProgressEvent) chain.ProgressReporter {
+	return chain.ProgressReporterFunc(func(event chain.ProgressEvent) {
+		*events = append(*events, event)
+	})
+}
+
+func TestChain_Progress(t *testing.T) {
+	events := []chain.ProgressEvent{}
+
+	c := chain.NewChain(
+		basics.NewStrLink(),
+		chain.NewChain(
+			basics.NewEchoLink(),
+		).WithName("nested"),
+	).WithName("progress").WithProgress(time.Hour, recordProgress(&events)).WithExpectedInputs(3)
+
+	c.Send("one", "two", "three")
+	c.Close()
+	c.Wait()
+	require.NoError(t, c.Error())
+
+	require.Len(t, events, 2, "should report once when started and once when finished")
+	assert.False(t, events[0].Done)
+	assert.Equal(t, int64(0), events[0].Completed())
+
+	last := events[1]
+	assert.True(t, last.Done)
+	assert.Equal(t, "progress", last.Chain)
+	assert.Equal(t, 3, last.Total)
+	assert.Equal(t, int64(3), last.Sent)
+	assert.Equal(t, []chain.LinkProgress{
+		{Link: "progress/*basics.StrLink", Completed: 3},
+		{Link: "progress/nested/*basics.EchoLink", Completed: 3},
+	}, last.Links)
+
+	fraction, ok := last.Fraction()
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, fraction)
+}
+
+func TestChain_ProgressFailedAndUnknownTotal(t *testing.T) {
+	events := []chain.ProgressEvent{}
+
+	c := chain.NewMulti(
+		chain.NewChain(basics.NewProcessErrorLink()).WithName("failing"),
+		chain.NewChain(basics.NewEchoLink()).WithName("echoing"),
+	).WithName("multi").WithProgress(time.Millisecond, recordProgress(&events)).WithStrictness(chain.Lax)
+
+	c.Send("one", "two")
+	c.Close()
+	c.Wait()
+
+	last := events[len(events)-1]
+	assert.True(t, last.Done)
+	assert.Equal(t, chain.UnknownInputCount, last.Total)
+	assert.Equal(t, []chain.LinkProgress{
+		{Link: "multi/failing/*basics.ProcessErrorLink", Completed: 2, Failed: 2},
+		{Link: "multi/echoing/*basics.EchoLink", Completed: 2},
+	}, last.Links)
+
+	_, ok := last.Fraction()
+	assert.False(t, ok)
+}
+
+func TestModule_Progress(t *testing.T) {
+	events := []chain.ProgressEvent{}
+
+	module := chain.NewModule(
+		cfg.NewMetadata("progress", "reports progress").WithChainInputParam("strings"),
+	).WithLinks(
+		basics.NewStrLink,
+	).WithConfigs(
+		cfg.WithArg("writer", &bytes.Buffer{}),
+	).WithInputParam(
+		cfg.NewParam[[]string]("strings", "strings to process"),
+	).WithOutputters(
+		output.NewWriterOutputter,
+	).WithProgress(time.Hour, recordProgress(&events))
+
+	require.NoError(t, module.Run(cfg.WithCLIArgs([]string{"-strings", "1,2,3,4,5"})))
+
+	last := events[len(events)-1]
+	assert.True(t, last.Done)
+	assert.Equal(t, 5, last.Total, "module should count its inputs")
+	assert.Equal(t, int64(5), last.Completed())
+}
+
+func TestProgressBar(t *testing.T) {
+	started := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := chain.ProgressEvent{
+		Chain:   "scan",
+		Started: started,
+		Time:    started.Add(90 * time.Second),
+		Total:   4,
+		Links: []chain.LinkProgress{
+			{Link: "scan/*links.Resolve", Completed: 1},
+			{Link: "scan/nested/*links.Probe", Completed: 3, Failed: 1},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	bar := chain.NewProgressBar(buf)
+	bar.ReportProgress(event)
+
+	event.Total = chain.UnknownInputCount
+	bar.ReportProgress(event)
+
+	assert.Equal(t, "scan [#######.......................] 1/4 (25%) 1m30s | *links.Resolve 1 | *links.Probe 3 (1 failed)\n"+
+		"scan 1 inputs 1m30s | *links.Resolve 1 | *links.Probe 3 (1 failed)\n", buf.String())
+}
+
+func TestProgressStream(t *testing.T) {
+	buf := &bytes.Buffer{}
+	stream := chain.NewProgressStream(buf)
+	stream.ReportProgress(chain.ProgressEvent{Chain: "scan", Total: 2, Sent: 1, Links: []chain.LinkProgress{{Link: "scan/*links.Resolve", Completed: 1}}})
+	stream.ReportProgress(chain.ProgressEvent{Chain: "scan", Total: 2, Sent: 2, Done: true})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var event chain.ProgressEvent
+	require.NoError(t, json.Unmarshal(lines[0], &event))
+	assert.Equal(t, "scan", event.Chain)
+	assert.Equal(t, int64(1), event.Sent)
+	assert.Equal(t, []chain.LinkProgress{{Link: "scan/*links.Resolve", Completed: 1}}, event.Links)
+
+	require.NoError(t, json.Unmarshal(lines[1], &event))
+	assert.True(t, event.Done)
+}
@@ -2,20 +2,34 @@ package chain
 
 import (
 	"fmt"
+	"iter"
 	"log/slog"
 	"reflect"
+
+	"github.com/praetorian-inc/janus-framework/pkg/util"
 )
 
 func RecvAs[T any](link Link) (T, bool) {
-	if chain, ok := link.(*BaseChain); ok {
-		chain.startIfUnstarted()
-	}
+	startIfUnstarted(link)
 
 	v, ok := <-link.channel()
 	if !ok {
 		return *new(T), false
 	}
 
+	cast, err := convertOutput[T](v)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to receive value from: %T", link), "error", err)
+		return *new(T), false
+	}
+
+	return cast, true
+}
+
+// convertOutput converts an item received from a chain, opening its envelope, to T.
+func convertOutput[T any](v any) (T, error) {
+	v, _ = openEnvelope(v)
+
 	outputType := reflect.TypeOf(*new(T))
 	if outputType == nil {
 		outputType = reflect.TypeOf((*any)(nil)).Elem()
@@ -23,9 +37,45 @@ func RecvAs[T any](link Link) (T, bool) {
 
 	cast, err := Convert(v, outputType)
 	if err != nil {
-		slog.Error(fmt.Sprintf("failed to receive value from: %T", link), "error", err)
-		return *new(T), false
+		return *new(T), err
+	}
+	return cast.Interface().(T), nil
+}
+
+// All yields each output of link converted to T, starting link if it has not started yet. It
+// works with chains, multi-chains and hoppers. An output that cannot be converted to T is
+// yielded with its error, and iteration carries on with the next output. If the loop breaks
+// early, the remaining outputs are drained in the background so the chain can finish; the
+// caller still closes the chain.
+func All[T any](link Link) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		startIfUnstarted(link)
+
+		out := link.channel()
+		for v := range out {
+			if !yield(convertOutput[T](v)) {
+				go util.EmptyChannel(out)
+				return
+			}
+		}
 	}
+}
 
-	return cast.Interface().(T), true
+// Items yields each output of link as All does, without converting them.
+func Items(link Link) iter.Seq[any] {
+	return func(yield func(any) bool) {
+		for item := range All[any](link) {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// startIfUnstarted starts link if it is a chain or hopper that starts itself when it is first
+// used, so its outputs can be received.
+func startIfUnstarted(link Link) {
+	if starter, ok := link.(interface{ startIfUnstarted() }); ok {
+		starter.startIfUnstarted()
+	}
 }
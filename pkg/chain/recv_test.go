@@ -0,0 +1,146 @@
+package chain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cherrors"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAll(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewStrLink(),
+		basics.NewStrIntLink(),
+	)
+
+	go func() {
+		c.Send("1", "2", "3")
+		c.Close()
+	}()
+
+	received := []int{}
+	for output, err := range chain.All[int](c) {
+		require.NoError(t, err)
+		received = append(received, output)
+	}
+	assert.Equal(t, []int{1, 2, 3}, received)
+}
+
+func TestAll_MultiChain(t *testing.T) {
+	add := func(i int) int { return i + 1 }
+	sub := func(i int) int { return i - 1 }
+
+	m := chain.NewMulti(
+		chain.NewChain(basics.NewIntLink(cfg.WithArg("intOp", add))),
+		chain.NewChain(basics.NewIntLink(cfg.WithArg("intOp", sub))),
+	)
+
+	go func() {
+		m.Send(10)
+		m.Close()
+	}()
+
+	received := []int{}
+	for output, err := range chain.All[int](m) {
+		require.NoError(t, err)
+		received = append(received, output)
+	}
+	assert.ElementsMatch(t, []int{9, 11}, received)
+}
+
+func TestAll_Hopper(t *testing.T) {
+	chain1 := chain.NewChain(basics.NewStrLink())
+	chain2 := chain.NewChain(basics.NewStrLink())
+	hopper := chain.NewHopper(chain1, chain2)
+
+	go func() {
+		chain1.Send("hello")
+		chain2.Send("world")
+		chain1.Close()
+		chain2.Close()
+	}()
+
+	received := []string{}
+	for output, err := range chain.All[string](hopper) {
+		require.NoError(t, err)
+		received = append(received, output)
+	}
+	assert.ElementsMatch(t, []string{"hello", "world"}, received, "All should start the hopper")
+}
+
+func TestAll_ConversionErrors(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewEchoLink(),
+	)
+
+	go func() {
+		c.Send(1, "two", 3)
+		c.Close()
+	}()
+
+	received := []int{}
+	errs := []error{}
+	for output, err := range chain.All[int](c) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		received = append(received, output)
+	}
+
+	assert.Equal(t, []int{1, 3}, received, "a conversion error should not end the loop")
+	require.Len(t, errs, 1)
+	var conversionErr *cherrors.ConversionError
+	assert.ErrorAs(t, errs[0], &conversionErr)
+}
+
+func TestAll_BreakEarly(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewEchoLink(),
+	)
+
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for i := range 100 {
+			c.Send(i)
+		}
+		c.Close()
+	}()
+
+	for output, err := range chain.All[int](c) {
+		require.NoError(t, err)
+		assert.Equal(t, 0, output)
+		break
+	}
+
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sending should not block once the loop has stopped")
+	}
+	c.Wait()
+	assert.NoError(t, c.Error())
+}
+
+func TestItems(t *testing.T) {
+	c := chain.NewChain(
+		basics.NewEchoLink(),
+	).WithEnvelopes()
+
+	go func() {
+		c.Send("one", 2)
+		c.Close()
+	}()
+
+	received := []any{}
+	for item := range chain.Items(c) {
+		received = append(received, item)
+	}
+	assert.Equal(t, []any{"one", 2}, received, "items should be received without their envelopes")
+}
@@ -0,0 +1,161 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer chains create their spans with.
+const TracerName = "github.com/praetorian-inc/janus-framework"
+
+// WithTracerProvider makes the chain record OpenTelemetry spans with the given provider: one
+// span for the chain's run, a child span for each call to a link's Process method, and spans
+// for the external commands links run through ExecuteCmd and ExecuteCmdAll. Nested chains
+// record their spans with the provider of the chain they are nested in.
+func (c *BaseChain) WithTracerProvider(provider trace.TracerProvider) Chain {
+	c.tracerProvider = provider
+	return c.super
+}
+
+// TraceContext returns the link's context carrying the span of the item it is processing, so
+// calls the link makes, such as HTTP requests, can be traced as its children. Without tracing,
+// it is the link's context.
+func (b *Base) TraceContext() context.Context {
+	if span := b.processSpan.Load(); span != nil {
+		return trace.ContextWithSpan(b.Context(), *span)
+	}
+	if b.traceParent != nil {
+		return trace.ContextWithSpan(b.Context(), trace.SpanFromContext(b.traceParent))
+	}
+	return b.Context()
+}
+
+func (b *Base) setTraceParent(ctx context.Context) {
+	b.traceParent = ctx
+}
+
+// startSpan starts the span of the chain's run, if the chain or the chain it is nested in
+// has a tracer provider.
+func (c *BaseChain) startSpan() {
+	parent := c.traceParent
+	var provider trace.TracerProvider
+	switch {
+	case c.tracerProvider != nil:
+		provider = c.tracerProvider
+		if parent == nil {
+			parent = c.Context()
+		}
+	case parent != nil:
+		provider = trace.SpanFromContext(parent).TracerProvider()
+	default:
+		return
+	}
+
+	ctx, span := provider.Tracer(TracerName).Start(parent, c.Name(),
+		trace.WithAttributes(attribute.String("janus.chain.name", c.Name())))
+	c.traceCtx = ctx
+	c.span = span
+}
+
+// traceChild makes child's spans children of the chain's span.
+func (c *BaseChain) traceChild(child Link) {
+	if c.traceCtx != nil {
+		child.setTraceParent(c.traceCtx)
+	}
+}
+
+func (c *BaseChain) endSpan() {
+	if c.span == nil {
+		return
+	}
+	endSpan(c.span, c.getError())
+}
+
+// startProcessSpan starts the span of one call to the link's Process method. It returns nil
+// without tracing.
+func (b *Base) startProcessSpan(value any) trace.Span {
+	if b.traceParent == nil {
+		return nil
+	}
+
+	tracer := trace.SpanFromContext(b.traceParent).TracerProvider().Tracer(TracerName)
+	_, span := tracer.Start(b.traceParent, "Process "+b.Name(), trace.WithAttributes(
+		attribute.String("janus.link.name", b.Name()),
+		attribute.String("janus.link.path", linkPath(b.super)),
+		attribute.String("janus.item.type", fmt.Sprintf("%T", value)),
+	))
+	b.processSpan.Store(&span)
+	return span
+}
+
+func (b *Base) endProcessSpan(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	b.processSpan.Store(nil)
+	endSpan(span, err)
+}
+
+// ExecuteCmd runs cmd through the link's injected methods, in a span when tracing and timed
+// when recording metrics.
+func (b *Base) ExecuteCmd(cmd *exec.Cmd, lineparser func(string)) error {
+	name := executableName(cmd)
+	span := b.startCmdSpan("ExecuteCmd", name)
+	started := time.Now()
+	err := b.MethodsHolder.ExecuteCmd(cmd, lineparser)
+	b.recordCommand(name, time.Since(started), err)
+	endCmdSpan(span, cmd, err)
+	return err
+}
+
+// ExecuteCmdAll runs cmd through the link's injected methods, in a span when tracing and timed
+// when recording metrics.
+func (b *Base) ExecuteCmdAll(cmd *exec.Cmd) ([]byte, error) {
+	name := executableName(cmd)
+	span := b.startCmdSpan("ExecuteCmdAll", name)
+	started := time.Now()
+	output, err := b.MethodsHolder.ExecuteCmdAll(cmd)
+	b.recordCommand(name, time.Since(started), err)
+	endCmdSpan(span, cmd, err)
+	return output, err
+}
+
+func executableName(cmd *exec.Cmd) string {
+	if len(cmd.Args) > 0 {
+		return filepath.Base(cmd.Args[0])
+	}
+	return filepath.Base(cmd.Path)
+}
+
+func (b *Base) startCmdSpan(method, name string) trace.Span {
+	ctx := b.TraceContext()
+	_, span := trace.SpanFromContext(ctx).TracerProvider().Tracer(TracerName).Start(ctx, method+" "+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("janus.link.name", b.Name()),
+			attribute.String("process.executable.name", name),
+		))
+	return span
+}
+
+func endCmdSpan(span trace.Span, cmd *exec.Cmd, err error) {
+	if cmd.ProcessState != nil {
+		span.SetAttributes(attribute.Int("process.exit.code", cmd.ProcessState.ExitCode()))
+	}
+	endSpan(span, err)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
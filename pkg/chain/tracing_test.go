@@ -0,0 +1,144 @@
+package chain_test
+
+import (
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestTracerProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
+
+func spansNamed(spans tracetest.SpanStubs, name string) []tracetest.SpanStub {
+	named := []tracetest.SpanStub{}
+	for _, span := range spans {
+		if span.Name == name {
+			named = append(named, span)
+		}
+	}
+	return named
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestChain_Tracing(t *testing.T) {
+	provider, exporter := newTestTracerProvider()
+
+	c := chain.NewChain(
+		basics.NewStrLink(),
+		chain.NewChain(
+			basics.NewStrIntLink(),
+		).WithName("nested"),
+	).WithName("traced").WithTracerProvider(provider)
+
+	c.Send("1", "2")
+	c.Close()
+	c.Wait()
+	require.NoError(t, c.Error())
+
+	spans := exporter.GetSpans()
+	chainSpans := spansNamed(spans, "traced")
+	require.Len(t, chainSpans, 1)
+	root := chainSpans[0]
+	assert.False(t, root.Parent.IsValid())
+
+	strSpans := spansNamed(spans, "Process *basics.StrLink")
+	require.Len(t, strSpans, 2)
+	for _, span := range strSpans {
+		assert.Equal(t, root.SpanContext.SpanID(), span.Parent.SpanID())
+		assert.Equal(t, "string", spanAttribute(span, "janus.item.type").AsString())
+		assert.Equal(t, codes.Unset, span.Status.Code)
+	}
+
+	nested := spansNamed(spans, "nested")
+	require.Len(t, nested, 1)
+	assert.Equal(t, root.SpanContext.SpanID(), nested[0].Parent.SpanID(), "nested chains should be traced within their parent")
+
+	intSpans := spansNamed(spans, "Process *basics.StrIntLink")
+	require.Len(t, intSpans, 2)
+	for _, span := range intSpans {
+		assert.Equal(t, nested[0].SpanContext.SpanID(), span.Parent.SpanID())
+		assert.Equal(t, root.SpanContext.TraceID(), span.SpanContext.TraceID())
+	}
+}
+
+func TestChain_TracingErrors(t *testing.T) {
+	provider, exporter := newTestTracerProvider()
+
+	c := chain.NewChain(
+		basics.NewErrorLink(cfg.WithArg("errorAt", "process")),
+	).WithName("failing").WithTracerProvider(provider)
+
+	c.Send("input")
+	c.Close()
+	c.Wait()
+	require.Error(t, c.Error())
+
+	spans := exporter.GetSpans()
+	process := spansNamed(spans, "Process *basics.ErrorLink")
+	require.Len(t, process, 1)
+	assert.Equal(t, codes.Error, process[0].Status.Code)
+	require.NotEmpty(t, process[0].Events)
+	assert.Equal(t, "exception", process[0].Events[0].Name)
+
+	root := spansNamed(spans, "failing")
+	require.Len(t, root, 1)
+	assert.Equal(t, codes.Error, root[0].Status.Code)
+}
+
+func TestChain_TracingExecuteCmd(t *testing.T) {
+	provider, exporter := newTestTracerProvider()
+
+	c := chain.NewChain(
+		mocks.NewExecutor(cfg.WithArg("cmd", "sh"), cfg.WithArg("args", []string{"-c", "echo traced; exit 3"})),
+	).WithTracerProvider(provider).WithStrictness(chain.Lax)
+
+	c.Send("run")
+	c.Close()
+	for _, ok := chain.RecvAs[string](c); ok; _, ok = chain.RecvAs[string](c) {
+	}
+
+	spans := exporter.GetSpans()
+	process := spansNamed(spans, "Process *mocks.Executor")
+	require.Len(t, process, 1)
+
+	cmd := spansNamed(spans, "ExecuteCmd sh")
+	require.Len(t, cmd, 1)
+	assert.Equal(t, process[0].SpanContext.SpanID(), cmd[0].Parent.SpanID())
+	assert.Equal(t, "sh", spanAttribute(cmd[0], "process.executable.name").AsString())
+	assert.Equal(t, int64(3), spanAttribute(cmd[0], "process.exit.code").AsInt64())
+	assert.Equal(t, codes.Error, cmd[0].Status.Code)
+}
+
+func TestChain_WithoutTracing(t *testing.T) {
+	c := chain.NewChain(basics.NewStrLink())
+
+	c.Send("untraced")
+	c.Close()
+
+	received := []string{}
+	for output, ok := chain.RecvAs[string](c); ok; output, ok = chain.RecvAs[string](c) {
+		received = append(received, output)
+	}
+
+	require.NoError(t, c.Error())
+	assert.Equal(t, []string{"untraced"}, received)
+}
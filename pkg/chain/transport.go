@@ -0,0 +1,162 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// Transport carries the items a link sends to the links consuming them. Links send over an
+// unbuffered channel unless given a transport with SetTransport; see the transport package for
+// a durable queue on disk.
+type Transport interface {
+	// Send adds item to the transport.
+	Send(item any) error
+	// Receive returns the next item, waiting for one if there is none yet, and a function to
+	// acknowledge it once it has been handled; a durable transport keeps the item until then.
+	// It returns io.EOF once the transport is closed and every item has been received.
+	Receive(ctx context.Context) (item any, ack func() error, err error)
+	// Close marks the end of the items. Receivers get the remaining items, then io.EOF.
+	Close() error
+}
+
+// SetTransport makes the link send its items through transport. The next link in the chain
+// receives them from transport, so a durable transport keeps the items that would otherwise
+// be lost in a crash, and workers in other processes can consume the link's items alongside
+// it with InputsFromTransport.
+func (b *Base) SetTransport(transport Transport) {
+	b.transport = transport
+}
+
+// transported is an item received from a transport, to be acknowledged once it has been
+// processed by the next link or output by the chain.
+type transported struct {
+	value any
+	ack   func() error
+}
+
+// fromTransport returns the item v carries if it was received from a transport, along with the
+// function acknowledging it. Other values are returned as they are, with an ack doing nothing.
+func fromTransport(v any) (any, func() error) {
+	if t, ok := v.(*transported); ok {
+		return t.value, t.ack
+	}
+	return v, func() error { return nil }
+}
+
+// shared returns t with an ack acknowledging t only once it has been called n times, so an
+// item dispersed to n chains is acknowledged once all of them have handled it.
+func (t *transported) shared(n int) *transported {
+	remaining := atomic.Int64{}
+	remaining.Store(int64(n))
+	return &transported{value: t.value, ack: func() error {
+		if remaining.Add(-1) == 0 {
+			return t.ack()
+		}
+		return nil
+	}}
+}
+
+// startTransport feeds the link's channel with the items received from its transport, until
+// the transport is closed.
+func (b *Base) startTransport(errHandler func(error)) {
+	if b.transport == nil {
+		return
+	}
+
+	b.transported = true
+	go func() {
+		defer close(b.ch)
+		for {
+			item, ack, err := b.transport.Receive(b.Context())
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				errHandler(fmt.Errorf("link %s failed to receive from transport: %w", b.Name(), err))
+				return
+			}
+			b.ch <- &transported{value: item, ack: ack}
+		}
+	}()
+}
+
+func (b *Base) sendToTransport(item any) error {
+	if err := b.transport.Send(item); err != nil {
+		err = fmt.Errorf("link %s failed to send to transport: %w", b.Name(), err)
+		b.Logger.Error("failed to send item", "error", err)
+		return err
+	}
+	return nil
+}
+
+// InputsFromTransport yields each item received from transport until it is closed, so worker
+// processes can consume the items a link in another process sends through a shared transport.
+// Each item is acknowledged once the chain's first link has processed it, so an item a worker
+// didn't get to process, e.g. because it crashed, is left for the transport to deliver again.
+// A failure to acknowledge an item is yielded as an error before the next item is received.
+func InputsFromTransport(transport Transport) InputSource {
+	return InputSourceFunc(func(_ Link) iter.Seq2[any, error] {
+		return func(yield func(any, error) bool) {
+			acks := &ackErrors{}
+			for {
+				item, ack, err := transport.Receive(context.Background())
+				if ackErr := acks.get(); ackErr != nil {
+					yield(nil, ackErr)
+					return
+				}
+				if errors.Is(err, io.EOF) {
+					return
+				}
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+
+				if !yield(&transported{value: item, ack: acks.record(ack)}, nil) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// ackErrors keeps the first error acknowledging the items of an InputsFromTransport, which
+// are acknowledged by the links processing them.
+type ackErrors struct {
+	mu  sync.Mutex
+	err error
+}
+
+// record returns ack, keeping the error it returns.
+func (a *ackErrors) record(ack func() error) func() error {
+	return func() error {
+		err := ack()
+		if err != nil {
+			a.mu.Lock()
+			if a.err == nil {
+				a.err = err
+			}
+			a.mu.Unlock()
+		}
+		return err
+	}
+}
+
+func (a *ackErrors) get() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// acknowledge acknowledges an item received from a transport, logging any failure: the item
+// has been handled, so the worst outcome is receiving it again.
+func (b *Base) acknowledge(ack func() error) {
+	if err := ack(); err != nil {
+		b.Logger.Error("failed to acknowledge item", "error", err)
+	}
+}
@@ -0,0 +1,328 @@
+// Package transport holds the chain.Transport implementations links can send their items
+// through instead of the default in-memory channel.
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+)
+
+const (
+	readyDir     = "ready"
+	claimedDir   = "claimed"
+	tmpDir       = "tmp"
+	closedMarker = "closed"
+
+	defaultPollInterval = 25 * time.Millisecond
+	defaultClaimTimeout = 10 * time.Minute
+)
+
+// DiskQueue is a chain.Transport keeping each item in a file of its own under a directory, so
+// the items survive a crash of the process sending them, and any number of processes on the
+// same host can receive them. Each item is received by a single receiver, and the items of
+// each sender are received in the order they were sent. A received item stays claimed by its
+// receiver until acknowledged, and is only then removed from the queue. Items whose receiver
+// did not acknowledge them within the claim timeout, e.g. because it crashed, are put back in
+// the queue when it is opened and whenever a receiver finds it empty, so each item is received
+// at least once. Receivers of a closed queue keep waiting while items are claimed, in case
+// they are put back.
+//
+// Items are stored as JSON along with their type name and lineage. Receivers decode each item
+// into the type of the prototype with the same name; strings, bools, ints, float64s and
+// []strings need no prototype, and items of other types decode into map[string]any.
+//
+// Once closed, a queue stays closed: use a new directory for each run.
+type DiskQueue struct {
+	dir          string
+	types        map[string]reflect.Type
+	poll         time.Duration
+	claimTimeout time.Duration
+	prefix       string // unique to this DiskQueue, so files it sends never collide with others'
+	seq          atomic.Uint64
+
+	mu    sync.Mutex // guards ready
+	ready []string   // names of the ready items last listed, oldest first, not yet claimed
+}
+
+type queuedItem struct {
+	Type    string          `json:"type"`
+	Value   json.RawMessage `json:"value"`
+	Lineage *chain.Lineage  `json:"lineage,omitempty"`
+}
+
+// NewDiskQueue opens the queue in dir, creating it if it does not exist. Received items are
+// decoded into the types of prototypes.
+func NewDiskQueue(dir string, prototypes ...any) (*DiskQueue, error) {
+	for _, sub := range []string{readyDir, claimedDir, tmpDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create queue directory: %w", err)
+		}
+	}
+
+	q := &DiskQueue{
+		dir:          dir,
+		types:        make(map[string]reflect.Type),
+		poll:         defaultPollInterval,
+		claimTimeout: defaultClaimTimeout,
+		prefix:       fmt.Sprintf("%020d-%d", time.Now().UnixNano(), os.Getpid()),
+	}
+	for _, prototype := range append([]any{"", false, 0, 0.0, []string{}}, prototypes...) {
+		t := reflect.TypeOf(prototype)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		q.types[t.String()] = t
+	}
+
+	if _, _, err := q.requeueExpired(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// WithClaimTimeout sets how long a received item may go unacknowledged before it is put back
+// in the queue for another receiver. It defaults to 10 minutes.
+func (q *DiskQueue) WithClaimTimeout(timeout time.Duration) *DiskQueue {
+	q.claimTimeout = timeout
+	return q
+}
+
+// Send writes item to the queue. The item is only visible to receivers once it has been
+// synced to disk.
+func (q *DiskQueue) Send(item any) error {
+	queued := queuedItem{}
+	value := item
+	if envelope, ok := item.(*chain.Envelope); ok {
+		value = envelope.Value
+		queued.Lineage = &envelope.Lineage
+	}
+	queued.Type = typeName(value)
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %T: %w", value, err)
+	}
+	queued.Value = raw
+
+	data, err := json.Marshal(queued)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%012d.json", q.prefix, q.seq.Add(1))
+	tmp := filepath.Join(q.dir, tmpDir, name)
+	if err := writeSynced(tmp, data); err != nil {
+		return fmt.Errorf("failed to write queued item: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(q.dir, readyDir, name)); err != nil {
+		return fmt.Errorf("failed to queue item: %w", err)
+	}
+	return nil
+}
+
+// Receive claims the oldest item in the queue, polling until there is one, the queue is
+// closed and every item has been acknowledged, or ctx is done. The item is removed from the
+// queue once ack is called.
+func (q *DiskQueue) Receive(ctx context.Context) (any, func() error, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		for len(q.ready) > 0 {
+			name := q.ready[0]
+			q.ready = q.ready[1:]
+			item, ack, claimed, err := q.claim(name)
+			if claimed || err != nil {
+				return item, ack, err
+			}
+		}
+
+		_, err := os.Stat(filepath.Join(q.dir, closedMarker))
+		closed := err == nil
+
+		if err := q.list(); err != nil {
+			return nil, nil, err
+		}
+		if len(q.ready) > 0 {
+			continue
+		}
+
+		requeued, pending, err := q.requeueExpired()
+		if err != nil {
+			return nil, nil, err
+		}
+		if requeued > 0 {
+			continue
+		}
+		// claimed items may yet be put back, so the queue is only done once none are left
+		if closed && pending == 0 {
+			return nil, nil, io.EOF
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(q.poll):
+		}
+	}
+}
+
+// list reads the names of the ready items, so they are not read again for every item received.
+func (q *DiskQueue) list() error {
+	entries, err := os.ReadDir(filepath.Join(q.dir, readyDir))
+	if err != nil {
+		return fmt.Errorf("failed to read queue: %w", err)
+	}
+
+	q.ready = q.ready[:0]
+	for _, entry := range entries {
+		q.ready = append(q.ready, entry.Name())
+	}
+	return nil
+}
+
+// claim moves the item out of the ready directory and decodes it. It returns false if another
+// receiver claimed it first.
+func (q *DiskQueue) claim(name string) (any, func() error, bool, error) {
+	claimed := filepath.Join(q.dir, claimedDir, fmt.Sprintf("%s.%s", name, q.prefix))
+	err := os.Rename(filepath.Join(q.dir, readyDir, name), claimed)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, false, nil
+	}
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to claim queued item: %w", err)
+	}
+
+	// the claim expires claimTimeout after now, not after the item was sent
+	now := time.Now()
+	if err := os.Chtimes(claimed, now, now); err != nil {
+		return nil, nil, true, fmt.Errorf("failed to claim queued item: %w", err)
+	}
+
+	ack := func() error {
+		err := os.Remove(claimed)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove queued item: %w", err)
+		}
+		return nil
+	}
+
+	data, err := os.ReadFile(claimed)
+	if err != nil {
+		return nil, nil, true, fmt.Errorf("failed to read queued item: %w", err)
+	}
+
+	item, err := q.decode(data)
+	if err != nil {
+		// an item that can't be decoded never will be, so it is not kept
+		return nil, nil, true, errors.Join(fmt.Errorf("failed to decode queued item %s: %w", name, err), ack())
+	}
+	return item, ack, true, nil
+}
+
+// requeueExpired puts the items that have been claimed for longer than the claim timeout back
+// in the ready directory, under their original names so they keep their place in the queue. It
+// returns how many items it put back, and how many are still claimed.
+func (q *DiskQueue) requeueExpired() (requeued, pending int, err error) {
+	entries, err := os.ReadDir(filepath.Join(q.dir, claimedDir))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read claimed items: %w", err)
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // acknowledged meanwhile
+		}
+		if err != nil {
+			return requeued, pending, fmt.Errorf("failed to read claimed item: %w", err)
+		}
+		if time.Since(info.ModTime()) < q.claimTimeout {
+			pending++
+			continue
+		}
+
+		name, _, _ := strings.Cut(entry.Name(), ".json.")
+		err = os.Rename(filepath.Join(q.dir, claimedDir, entry.Name()), filepath.Join(q.dir, readyDir, name+".json"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // acknowledged or requeued meanwhile
+		}
+		if err != nil {
+			return requeued, pending, fmt.Errorf("failed to requeue claimed item: %w", err)
+		}
+		requeued++
+	}
+	return requeued, pending, nil
+}
+
+func (q *DiskQueue) decode(data []byte) (any, error) {
+	queued := queuedItem{}
+	if err := json.Unmarshal(data, &queued); err != nil {
+		return nil, err
+	}
+
+	var value any
+	if t, ok := q.types[queued.Type]; ok {
+		ptr := reflect.New(t)
+		if err := json.Unmarshal(queued.Value, ptr.Interface()); err != nil {
+			return nil, err
+		}
+		value = ptr.Elem().Interface()
+	} else if err := json.Unmarshal(queued.Value, &value); err != nil {
+		return nil, err
+	}
+
+	if queued.Lineage != nil {
+		return &chain.Envelope{Value: value, Lineage: *queued.Lineage}, nil
+	}
+	return value, nil
+}
+
+// Close marks the queue closed. Receivers get the items left in it, then io.EOF once every item
+// has been acknowledged.
+func (q *DiskQueue) Close() error {
+	if err := writeSynced(filepath.Join(q.dir, closedMarker), nil); err != nil {
+		return fmt.Errorf("failed to close queue: %w", err)
+	}
+	return nil
+}
+
+func typeName(v any) string {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return "nil"
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.String()
+}
+
+func writeSynced(path string, data []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
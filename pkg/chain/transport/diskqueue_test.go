@@ -0,0 +1,211 @@
+package transport_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type target struct {
+	Host string
+	Port int
+}
+
+func receiveAll(t *testing.T, q *transport.DiskQueue) []any {
+	t.Helper()
+	items := []any{}
+	for {
+		item, ack, err := q.Receive(context.Background())
+		if err == io.EOF {
+			return items
+		}
+		require.NoError(t, err)
+		require.NoError(t, ack())
+		items = append(items, item)
+	}
+}
+
+func TestDiskQueue(t *testing.T) {
+	q, err := transport.NewDiskQueue(t.TempDir(), target{})
+	require.NoError(t, err)
+
+	lineage := chain.Lineage{RunID: "run", TraceID: "trace", Origin: "example.com", Links: []string{"*links.Resolve"}}
+	require.NoError(t, q.Send("one"))
+	require.NoError(t, q.Send(2))
+	require.NoError(t, q.Send(&target{Host: "example.com", Port: 443}))
+	require.NoError(t, q.Send(map[string]any{"unregistered": true}))
+	require.NoError(t, q.Send(&chain.Envelope{Value: "enveloped", Lineage: lineage}))
+	require.NoError(t, q.Close())
+
+	assert.Equal(t, []any{
+		"one",
+		2,
+		target{Host: "example.com", Port: 443},
+		map[string]any{"unregistered": true},
+		&chain.Envelope{Value: "enveloped", Lineage: lineage},
+	}, receiveAll(t, q))
+}
+
+func TestDiskQueue_SurvivesReopening(t *testing.T) {
+	dir := t.TempDir()
+
+	sender, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+	require.NoError(t, sender.Send("one"))
+	require.NoError(t, sender.Send("two"))
+	// the sender stops without closing the queue, as it would in a crash
+
+	receiver, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+	item, _, err := receiver.Receive(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "one", item)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	item, _, err = receiver.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "two", item)
+
+	_, _, err = receiver.Receive(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "an open queue should wait for more items")
+}
+
+func TestDiskQueue_ConcurrentReceivers(t *testing.T) {
+	dir := t.TempDir()
+
+	sender, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+
+	mu := sync.Mutex{}
+	received := []int{}
+	wg := sync.WaitGroup{}
+	for range 3 {
+		receiver, err := transport.NewDiskQueue(dir)
+		require.NoError(t, err)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				item, ack, err := receiver.Receive(context.Background())
+				if err != nil {
+					assert.ErrorIs(t, err, io.EOF)
+					return
+				}
+				assert.NoError(t, ack())
+				mu.Lock()
+				received = append(received, item.(int))
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range 50 {
+		require.NoError(t, sender.Send(i))
+	}
+	require.NoError(t, sender.Close())
+	wg.Wait()
+
+	sort.Ints(received)
+	expected := make([]int, 50)
+	for i := range expected {
+		expected[i] = i
+	}
+	assert.Equal(t, expected, received, "each item should be received exactly once")
+}
+
+func TestDiskQueue_KeepsUnacknowledgedItems(t *testing.T) {
+	dir := t.TempDir()
+
+	sender, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+	require.NoError(t, sender.Send("one"))
+	require.NoError(t, sender.Send("two"))
+	require.NoError(t, sender.Close())
+
+	crashed, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+	item, _, err := crashed.Receive(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "one", item)
+	// the receiver crashes before acknowledging the item
+
+	claimed, err := os.ReadDir(filepath.Join(dir, "claimed"))
+	require.NoError(t, err)
+	assert.Len(t, claimed, 1, "an unacknowledged item should stay in the queue")
+
+	receiver, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+	item, ack, err := receiver.Receive(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "two", item, "an item claimed within the timeout should not be received again")
+	require.NoError(t, ack())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = receiver.Receive(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "a closed queue should wait while an item is claimed")
+
+	expired := filepath.Join(dir, "claimed", claimed[0].Name())
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(expired, past, past))
+	recovered, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"one"}, receiveAll(t, recovered), "an expired claim should be put back in the queue on open")
+
+	claimed, err = os.ReadDir(filepath.Join(dir, "claimed"))
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "acknowledged items should be removed")
+}
+
+func TestDiskQueue_RequeuesExpiredClaimsWhileWaiting(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+	q.WithClaimTimeout(10 * time.Millisecond)
+	require.NoError(t, q.Send("one"))
+
+	item, _, err := q.Receive(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "one", item)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	item, ack, err := q.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "one", item, "an item left unacknowledged past the timeout should be received again")
+	require.NoError(t, ack())
+}
+
+func TestDiskQueue_RequeuesExpiredClaimsOnceClosed(t *testing.T) {
+	dir := t.TempDir()
+
+	sender, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+	require.NoError(t, sender.Send("one"))
+	require.NoError(t, sender.Close())
+
+	crashed, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+	item, _, err := crashed.Receive(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "one", item)
+	// the receiver crashes before acknowledging the item
+
+	receiver, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+	receiver.WithClaimTimeout(10 * time.Millisecond)
+	assert.Equal(t, []any{"one"}, receiveAll(t, receiver), "an expired claim should be received again before the closed queue ends")
+}
@@ -0,0 +1,107 @@
+package chain_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/transport"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain_Transport(t *testing.T) {
+	dir := t.TempDir()
+	queue, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+
+	first := basics.NewStrLink()
+	first.SetTransport(queue)
+	outputter := NewTestRecordingOutputter()
+
+	c := chain.NewChain(
+		first,
+		basics.NewEchoLink(),
+	).WithOutputters(outputter).WithStrictness(chain.Strict)
+
+	c.Send("one", "two", "three")
+	c.Close()
+	c.Wait()
+
+	require.NoError(t, c.Error())
+	assert.Equal(t, []any{"one", "two", "three"}, outputter.items)
+
+	claimed, err := os.ReadDir(filepath.Join(dir, "claimed"))
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "items should be acknowledged once processed")
+}
+
+func TestInputsFromTransport(t *testing.T) {
+	queue, err := transport.NewDiskQueue(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, queue.Send("one"))
+	require.NoError(t, queue.Send(&chain.Envelope{Value: "two", Lineage: chain.Lineage{RunID: "producer"}}))
+	require.NoError(t, queue.Close())
+
+	outputter := NewTestEnvelopeOutputter()
+	worker := chain.NewChain(
+		basics.NewEchoLink(),
+	).WithOutputters(outputter).WithEnvelopes()
+
+	for input, err := range chain.InputsFromTransport(queue).Inputs(nil) {
+		require.NoError(t, err)
+		require.NoError(t, worker.Send(input))
+	}
+	worker.Close()
+	worker.Wait()
+
+	require.NoError(t, worker.Error())
+	require.Len(t, outputter.items, 2)
+	assert.Equal(t, "one", outputter.items[0].(*chain.Envelope).Value)
+	assert.Equal(t, "two", outputter.items[1].(*chain.Envelope).Value)
+	assert.Equal(t, "producer", outputter.items[1].(*chain.Envelope).RunID, "items from another process should keep their lineage")
+}
+
+func TestInputsFromTransport_AcknowledgesAfterProcessing(t *testing.T) {
+	dir := t.TempDir()
+	queue, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+	require.NoError(t, queue.Send("one"))
+	require.NoError(t, queue.Send("two"))
+	require.NoError(t, queue.Close())
+
+	for input, err := range chain.InputsFromTransport(queue).Inputs(nil) {
+		require.NoError(t, err)
+		require.NotNil(t, input)
+		break // the worker crashes before sending the item to its chain
+	}
+
+	claimed, err := os.ReadDir(filepath.Join(dir, "claimed"))
+	require.NoError(t, err)
+	assert.Len(t, claimed, 1, "an item the worker didn't process should stay in the queue")
+
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "claimed", claimed[0].Name()), time.Time{}, time.Now().Add(-time.Hour)))
+	outputter := NewTestRecordingOutputter()
+	worker := chain.NewChain(
+		basics.NewEchoLink(),
+	).WithOutputters(outputter)
+
+	recovered, err := transport.NewDiskQueue(dir)
+	require.NoError(t, err)
+	for input, err := range chain.InputsFromTransport(recovered).Inputs(nil) {
+		require.NoError(t, err)
+		require.NoError(t, worker.Send(input))
+	}
+	worker.Close()
+	worker.Wait()
+
+	require.NoError(t, worker.Error())
+	assert.Equal(t, []any{"one", "two"}, outputter.items)
+
+	claimed, err = os.ReadDir(filepath.Join(dir, "claimed"))
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "items should be acknowledged once processed")
+}
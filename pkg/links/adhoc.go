@@ -1,13 +1,16 @@
 package links
 
 import (
+	"reflect"
+
 	"github.com/praetorian-inc/janus-framework/pkg/chain"
 	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
 )
 
 type AdHocLink[T any] struct {
 	*chain.Base
-	processFn func(self chain.Link, input T) error
+	processFn   func(self chain.Link, input T) error
+	outputTypes []reflect.Type
 }
 
 func NewAdHocLink[T any](processFn func(self chain.Link, input T) error, configs ...cfg.Config) chain.Link {
@@ -21,6 +24,16 @@ func (l *AdHocLink[T]) Process(input T) error {
 	return l.processFn(l, input)
 }
 
+func (l *AdHocLink[T]) OutputTypes() []reflect.Type {
+	return l.outputTypes
+}
+
+func newTypedAdHocLink[I, O any](processFn func(self chain.Link, input I) error, configs ...cfg.Config) chain.Link {
+	l := NewAdHocLink(processFn, configs...).(*AdHocLink[I])
+	l.outputTypes = []reflect.Type{reflect.TypeFor[O]()}
+	return l
+}
+
 func ConstructAdHocLink[T any](processFn func(self chain.Link, input T) error) func(...cfg.Config) chain.Link {
 	return func(configs ...cfg.Config) chain.Link {
 		return NewAdHocLink(processFn, configs...)
@@ -32,7 +45,7 @@ func FromWrapper[I, O any](wrapper func(I) O, configs ...cfg.Config) chain.Link
 		self.Send(wrapper(input))
 		return nil
 	}
-	return NewAdHocLink(process, configs...)
+	return newTypedAdHocLink[I, O](process, configs...)
 }
 
 func ConstructWrapper[I, O any](wrapper func(I) O) func(...cfg.Config) chain.Link {
@@ -50,7 +63,7 @@ func FromTransformer[I, O any](transformer func(I) (O, error)) chain.Link {
 		self.Send(output)
 		return nil
 	}
-	return NewAdHocLink(process)
+	return newTypedAdHocLink[I, O](process)
 }
 
 func FromTransformerSlice[I, O any](transformer func(I) ([]O, error)) chain.Link {
@@ -64,5 +77,5 @@ func FromTransformerSlice[I, O any](transformer func(I) ([]O, error)) chain.Link
 		}
 		return nil
 	}
-	return NewAdHocLink(process)
+	return newTypedAdHocLink[I, O](process)
 }
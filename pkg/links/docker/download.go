@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/praetorian-inc/tabularium/pkg/model/model"
+
 	"github.com/praetorian-inc/janus-framework/pkg/chain"
 	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
 	dockerTypes "github.com/praetorian-inc/janus-framework/pkg/types/docker"
@@ -28,6 +30,16 @@ func NewDockerDownload(configs ...cfg.Config) chain.Link {
 	return dd
 }
 
+func (dd *DockerDownloadLink) CredentialType() model.CredentialType {
+	return dockerTypes.RegistryCredentialType
+}
+
+// CredentialOptional lets the link fall back to the AuthConfig of its images when no provider
+// has a registry credential.
+func (dd *DockerDownloadLink) CredentialOptional() bool {
+	return true
+}
+
 func (dd *DockerDownloadLink) Params() []cfg.Param {
 	return []cfg.Param{
 		cfg.NewParam[string]("output", "output directory").
@@ -50,11 +62,23 @@ func (dd *DockerDownloadLink) Initialize() error {
 	return nil
 }
 
+func (dd *DockerDownloadLink) TargetFiles() []string {
+	dir, err := cfg.As[string](dd.Arg("output"))
+	if err != nil {
+		return nil
+	}
+	return []string{dir}
+}
+
 func (dd *DockerDownloadLink) Process(dockerImage *dockerTypes.DockerImage) error {
 	if dockerImage.Image == "" {
 		return fmt.Errorf("Docker image name is required")
 	}
-	dd.registryClient = *dockerTypes.NewDockerRegistryClient(dockerImage)
+	authenticated, err := authenticatedImage(dd, *dockerImage)
+	if err != nil {
+		return err
+	}
+	dd.registryClient = *dockerTypes.NewDockerRegistryClient(&authenticated).WithContext(dd.TraceContext())
 
 	outFile, err := createOutputFile(dd.outDir, dockerImage.Image)
 	if err != nil {
@@ -107,6 +131,7 @@ func (dd *DockerDownloadLink) downloadImageFromManifest(manifest *dockerTypes.Re
 	if err != nil {
 		return err
 	}
+	recordDownloaded(dd.Base, configData)
 
 	configPath := filepath.Join(outputDir, configFile)
 	if err := os.WriteFile(configPath, configData, 0644); err != nil {
@@ -161,6 +186,7 @@ func (dd *DockerDownloadLink) downloadLayer(layer dockerTypes.RegistryLayer, ima
 	if err != nil {
 		return layerTar, err
 	}
+	recordDownloaded(dd.Base, layerData)
 
 	return layerTar, os.WriteFile(layerPath, layerData, 0644)
 }
@@ -219,12 +245,26 @@ func NewDockerGetLayers(configs ...cfg.Config) chain.Link {
 	return dgl
 }
 
+func (dgl *DockerGetLayersLink) CredentialType() model.CredentialType {
+	return dockerTypes.RegistryCredentialType
+}
+
+// CredentialOptional lets the link fall back to the AuthConfig of its images when no provider
+// has a registry credential.
+func (dgl *DockerGetLayersLink) CredentialOptional() bool {
+	return true
+}
+
 func (dgl *DockerGetLayersLink) Process(dockerImage *dockerTypes.DockerImage) error {
 	if dockerImage.Image == "" {
 		return fmt.Errorf("Docker image name is required")
 	}
 
-	dgl.registryClient = *dockerTypes.NewDockerRegistryClient(dockerImage)
+	authenticated, err := authenticatedImage(dgl, *dockerImage)
+	if err != nil {
+		return err
+	}
+	dgl.registryClient = *dockerTypes.NewDockerRegistryClient(&authenticated).WithContext(dgl.TraceContext())
 	imageName, tag := dgl.registryClient.ParseImageName(dockerImage.Image)
 
 	if err := dgl.registryClient.RefreshToken(); err != nil {
@@ -284,12 +324,26 @@ func NewDockerDownloadLayer(configs ...cfg.Config) chain.Link {
 	return ddl
 }
 
+func (ddl *DockerDownloadLayerLink) CredentialType() model.CredentialType {
+	return dockerTypes.RegistryCredentialType
+}
+
+// CredentialOptional lets the link fall back to the AuthConfig of its images when no provider
+// has a registry credential.
+func (ddl *DockerDownloadLayerLink) CredentialOptional() bool {
+	return true
+}
+
 func (ddl *DockerDownloadLayerLink) Process(layer *dockerTypes.DockerLayer) error {
 	if layer.DockerImage == nil || layer.Digest == "" {
 		return fmt.Errorf("DockerImage and Digest are required")
 	}
 
-	ddl.registryClient = *dockerTypes.NewDockerRegistryClient(layer.DockerImage)
+	authenticated, err := authenticatedImage(ddl, *layer.DockerImage)
+	if err != nil {
+		return err
+	}
+	ddl.registryClient = *dockerTypes.NewDockerRegistryClient(&authenticated).WithContext(ddl.TraceContext())
 	imageName, _ := ddl.registryClient.ParseImageName(layer.DockerImage.Image)
 
 	if err := ddl.registryClient.RefreshToken(); err != nil {
@@ -300,7 +354,13 @@ func (ddl *DockerDownloadLayerLink) Process(layer *dockerTypes.DockerLayer) erro
 	if err != nil {
 		return fmt.Errorf("failed to download layer %s: %w", layer.Digest, err)
 	}
+	recordDownloaded(ddl.Base, layerData)
 
 	layer.Data = layerData
 	return ddl.Send(layer)
 }
+
+// recordDownloaded counts data downloaded from a registry in the link's metrics.
+func recordDownloaded(link *chain.Base, data []byte) {
+	link.Metrics().Counter(chain.MetricRegistryBytes, "Bytes downloaded from Docker registries.").Add(float64(len(data)))
+}
@@ -0,0 +1,136 @@
+package docker_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/links/docker"
+	dockerTypes "github.com/praetorian-inc/janus-framework/pkg/types/docker"
+)
+
+// testRegistry requires a token for layers, handing one out for the basic auth of any user.
+type testRegistry struct {
+	*httptest.Server
+	mu    sync.Mutex
+	users []string
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	r := &testRegistry{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/token":
+			username, _, _ := req.BasicAuth()
+			r.mu.Lock()
+			r.users = append(r.users, username)
+			r.mu.Unlock()
+			json.NewEncoder(w).Encode(map[string]string{"token": "token-for-" + username})
+		case req.Header.Get("Authorization") == "":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token"`, r.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.Write([]byte("layer"))
+		}
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// image names an image hosted by the registry.
+func (r *testRegistry) image(name string) string {
+	return strings.TrimPrefix(r.URL, "http://") + "/" + name
+}
+
+func (r *testRegistry) authenticatedUsers() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.users
+}
+
+func TestDockerDownloadLayer_Credential(t *testing.T) {
+	server := newTestRegistry(t)
+
+	c := chain.NewChain(
+		docker.NewDockerDownloadLayer(),
+	).WithCredentialProviders(
+		cfg.NewStaticCredentialProvider(
+			cfg.NewCredential(dockerTypes.RegistryCredentialType, map[string]string{"username": "provided", "password": "secret", "serveraddress": server.URL}),
+		),
+	)
+
+	layer := &dockerTypes.DockerLayer{
+		DockerImage: &dockerTypes.DockerImage{Image: server.image("library/nginx")},
+		Digest:      "sha256:abc",
+	}
+	outputs, err := chain.Invoke[*dockerTypes.DockerLayer](context.Background(), c, layer)
+	require.NoError(t, err)
+	require.Len(t, outputs, 1)
+
+	assert.Equal(t, []byte("layer"), outputs[0].Data)
+	assert.Equal(t, []string{"provided"}, server.authenticatedUsers(), "the link should authenticate with the provided credential")
+	assert.Empty(t, outputs[0].DockerImage.AuthConfig.Password, "the credential should not be sent on with the image")
+}
+
+func TestDockerDownloadLayer_AuthConfigFallback(t *testing.T) {
+	server := newTestRegistry(t)
+
+	c := chain.NewChain(
+		docker.NewDockerDownloadLayer(),
+	).WithCredentialProviders(
+		cfg.NewStaticCredentialProvider(),
+	)
+
+	layer := &dockerTypes.DockerLayer{
+		DockerImage: &dockerTypes.DockerImage{
+			Image:      server.image("library/nginx"),
+			AuthConfig: registry.AuthConfig{ServerAddress: server.URL, Username: "configured", Password: "secret"},
+		},
+		Digest: "sha256:abc",
+	}
+	outputs, err := chain.Invoke[*dockerTypes.DockerLayer](context.Background(), c, layer)
+	require.NoError(t, err)
+	require.Len(t, outputs, 1)
+
+	assert.Equal(t, []string{"configured"}, server.authenticatedUsers(), "without a provided credential, the image's AuthConfig should be used")
+}
+
+func TestDockerDownloadLayer_CredentialScopedToRegistry(t *testing.T) {
+	server := newTestRegistry(t)
+	other := newTestRegistry(t)
+
+	credentials := map[string]map[string]string{
+		"for another registry": {"username": "provided", "password": "secret", "serveraddress": server.URL},
+		"for Docker Hub":       {"username": "provided", "password": "secret"},
+	}
+	for name, values := range credentials {
+		t.Run(name, func(t *testing.T) {
+			c := chain.NewChain(
+				docker.NewDockerDownloadLayer(),
+			).WithCredentialProviders(
+				cfg.NewStaticCredentialProvider(cfg.NewCredential(dockerTypes.RegistryCredentialType, values)),
+			)
+
+			layer := &dockerTypes.DockerLayer{
+				DockerImage: &dockerTypes.DockerImage{Image: other.image("library/nginx"), AuthConfig: registry.AuthConfig{ServerAddress: other.URL}},
+				Digest:      "sha256:abc",
+			}
+			_, err := chain.Invoke[*dockerTypes.DockerLayer](context.Background(), c, layer)
+			require.NoError(t, err)
+
+			assert.NotContains(t, other.authenticatedUsers(), "provided", "the credential should not be sent to a registry it isn't for")
+		})
+	}
+	assert.Empty(t, server.authenticatedUsers())
+}
@@ -8,11 +8,14 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/client"
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
 	dockerTypes "github.com/praetorian-inc/janus-framework/pkg/types/docker"
 )
 
@@ -88,6 +91,47 @@ func authenticate(ctx context.Context, imageContext dockerTypes.DockerImage, ret
 	return dockerClient, nil
 }
 
+// authenticatedImage returns a copy of imageContext carrying the registry credential resolved
+// for link, so the credential is not sent on with the image. The credential is only used for
+// images from the registry it names; a credential without a server address is for Docker Hub.
+// Other images keep their own AuthConfig.
+func authenticatedImage(link chain.Link, imageContext dockerTypes.DockerImage) (dockerTypes.DockerImage, error) {
+	if link.Credential() == nil {
+		return imageContext, nil
+	}
+
+	auth, err := chain.CredentialAs[registry.AuthConfig](link)
+	if err != nil {
+		return imageContext, err
+	}
+	if !registryMatches(auth.ServerAddress, imageContext.RegistryHost()) {
+		return imageContext, nil
+	}
+
+	imageContext.AuthConfig = auth
+	return imageContext, nil
+}
+
+// dockerHubHosts are the hosts Docker Hub is addressed by.
+var dockerHubHosts = []string{dockerTypes.DefaultRegistryHost, "index.docker.io", "registry-1.docker.io"}
+
+// registryMatches reports whether serverAddress, such as "https://ghcr.io" or "ghcr.io",
+// addresses the registry at host. An empty serverAddress addresses Docker Hub.
+func registryMatches(serverAddress, host string) bool {
+	if serverAddress == "" {
+		return slices.Contains(dockerHubHosts, host)
+	}
+
+	domain, err := DockerExtractDomain(serverAddress)
+	if err != nil {
+		return false
+	}
+	if slices.Contains(dockerHubHosts, domain) {
+		return slices.Contains(dockerHubHosts, host)
+	}
+	return strings.EqualFold(domain, host)
+}
+
 func removeImage(ctx context.Context, dockerClient *client.Client, imageID string) {
 	_, err := dockerClient.ImageRemove(ctx, imageID, image.RemoveOptions{Force: true})
 	if err != nil {
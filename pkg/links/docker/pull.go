@@ -10,6 +10,8 @@ import (
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/client"
+	"github.com/praetorian-inc/tabularium/pkg/model/model"
+
 	"github.com/praetorian-inc/janus-framework/pkg/chain"
 	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
 	dockerTypes "github.com/praetorian-inc/janus-framework/pkg/types/docker"
@@ -26,20 +28,33 @@ func NewDockerPull(configs ...cfg.Config) chain.Link {
 	return dp
 }
 
+func (dp *DockerPull) CredentialType() model.CredentialType {
+	return dockerTypes.RegistryCredentialType
+}
+
+// CredentialOptional lets the link fall back to the AuthConfig of its images when no provider
+// has a registry credential.
+func (dp *DockerPull) CredentialOptional() bool {
+	return true
+}
+
 func (dp *DockerPull) Process(imageContext dockerTypes.DockerImage) error {
 	imageContext.Image = strings.TrimSpace(imageContext.Image)
 	if imageContext.Image == "" {
 		return nil
 	}
 
-	isPublicImage := strings.Contains(imageContext.AuthConfig.ServerAddress, "public.ecr.aws")
+	authenticated, err := authenticatedImage(dp, imageContext)
+	if err != nil {
+		return err
+	}
+	isPublicImage := strings.Contains(authenticated.AuthConfig.ServerAddress, "public.ecr.aws")
 
 	var dockerClient *client.Client
-	var err error
 	var pullOpts image.PullOptions
 
 	if !isPublicImage {
-		dockerClient, err = dp.authenticate(imageContext, &pullOpts, client.FromEnv)
+		dockerClient, err = dp.authenticate(authenticated, &pullOpts, client.FromEnv)
 	} else {
 		dockerClient, err = NewUnauthenticatedClient(dp.Context(), client.FromEnv)
 	}
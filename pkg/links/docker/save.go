@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/docker/docker/client"
+	"github.com/praetorian-inc/tabularium/pkg/model/model"
+
 	"github.com/praetorian-inc/janus-framework/pkg/chain"
 	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
 	dockerTypes "github.com/praetorian-inc/janus-framework/pkg/types/docker"
@@ -49,14 +51,35 @@ func (dsl *DockerSave) Initialize() error {
 	return nil
 }
 
+func (dsl *DockerSave) TargetFiles() []string {
+	dir, err := cfg.As[string](dsl.Arg("output"))
+	if err != nil || dir == "" {
+		dir = filepath.Join(os.TempDir(), ".janus-docker-images")
+	}
+	return []string{dir}
+}
+
+func (dsl *DockerSave) CredentialType() model.CredentialType {
+	return dockerTypes.RegistryCredentialType
+}
+
+// CredentialOptional lets the link fall back to the AuthConfig of its images when no provider
+// has a registry credential.
+func (dsl *DockerSave) CredentialOptional() bool {
+	return true
+}
+
 func (dsl *DockerSave) Process(imageContext dockerTypes.DockerImage) error {
-	isPublicImage := strings.Contains(imageContext.AuthConfig.ServerAddress, "public.ecr.aws")
+	authenticated, err := authenticatedImage(dsl, imageContext)
+	if err != nil {
+		return err
+	}
+	isPublicImage := strings.Contains(authenticated.AuthConfig.ServerAddress, "public.ecr.aws")
 
 	var dockerClient *client.Client
-	var err error
 
 	if !isPublicImage {
-		dockerClient, err = NewAuthenticatedClient(dsl.Context(), imageContext, client.FromEnv)
+		dockerClient, err = NewAuthenticatedClient(dsl.Context(), authenticated, client.FromEnv)
 	} else {
 		dockerClient, err = NewUnauthenticatedClient(dsl.Context(), client.FromEnv)
 	}
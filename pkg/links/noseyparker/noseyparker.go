@@ -93,6 +93,18 @@ func (n *NoseyParkerScanner) Initialize() error {
 	return nil
 }
 
+func (n *NoseyParkerScanner) Binaries() []string {
+	return []string{"noseyparker"}
+}
+
+func (n *NoseyParkerScanner) TargetFiles() []string {
+	datastore, err := cfg.As[string](n.Arg("datastore"))
+	if err != nil {
+		return nil
+	}
+	return []string{datastore}
+}
+
 func (n *NoseyParkerScanner) Process(resource types.NPInput) error {
 	encoder := json.NewEncoder(n.npStdin)
 	if err := encoder.Encode(resource); err != nil {
@@ -174,6 +186,10 @@ func (n *NoseyParkerSummarizer) Initialize() error {
 	return nil
 }
 
+func (n *NoseyParkerSummarizer) Binaries() []string {
+	return []string{"noseyparker"}
+}
+
 func (n *NoseyParkerSummarizer) Process(resource any) error {
 	return nil
 }
@@ -224,6 +240,10 @@ func (n *NoseyParkerReporter) Initialize() error {
 	return nil
 }
 
+func (n *NoseyParkerReporter) Binaries() []string {
+	return []string{"noseyparker"}
+}
+
 func (n *NoseyParkerReporter) Process(resource any) error {
 	return nil
 }
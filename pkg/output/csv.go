@@ -0,0 +1,349 @@
+package output
+
+import (
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+)
+
+// CSVOutputter writes items as rows of a CSV or TSV file, one row per item as it arrives.
+//
+// Columns come from the fields of struct items, named by their `janus:"col"` tag, else their
+// json tag, else the field name. Nested structs are flattened into dotted names, e.g.
+// "provenance.repo_path"; slices and maps are written as JSON. Fields tagged `janus:"-"` or
+// `json:"-"` are skipped. Map items use their keys as columns, and any other item is written
+// to a single "value" column.
+//
+// Unless the columns param is set, the columns are those of the first item. With
+// encrypt-passphrase or encrypt-recipient set, the file is encrypted (see NewDecryptReader).
+type CSVOutputter struct {
+	*chain.BaseOutputter
+	file      *outputFile
+	writer    *csv.Writer
+	columns   []string
+	delimiter rune
+	outParam  string
+	escape    bool
+}
+
+// csvColumn is a column of a struct type: the path of field indexes leading to its value.
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+func NewCSVOutputter(configs ...cfg.Config) chain.Outputter {
+	c := &CSVOutputter{delimiter: ',', outParam: "csvoutfile"}
+	c.BaseOutputter = chain.NewBaseOutputter(c, configs...)
+	return c
+}
+
+func NewTSVOutputter(configs ...cfg.Config) chain.Outputter {
+	c := &CSVOutputter{delimiter: '\t', outParam: "tsvoutfile"}
+	c.BaseOutputter = chain.NewBaseOutputter(c, configs...)
+	return c
+}
+
+func (c *CSVOutputter) Params() []cfg.Param {
+	outfile := cfg.NewParam[string]("csvoutfile", "the file to write the CSV to").WithDefault("out.csv")
+	if c.delimiter == '\t' {
+		outfile = cfg.NewParam[string]("tsvoutfile", "the file to write the TSV to").WithDefault("out.tsv")
+	}
+
+	return append([]cfg.Param{
+		outfile,
+		cfg.NewParam[[]string]("csv-columns", "the columns to write, in order (defaults to the columns of the first item)"),
+		cfg.NewParam[bool]("csv-escape-formulas", "prefix cells starting with =, +, -, @, tab or carriage return so spreadsheets don't evaluate them").WithDefault(true),
+	}, encryptionParams()...)
+}
+
+func (c *CSVOutputter) Initialize() error {
+	filename, err := cfg.As[string](c.Arg(c.outParam))
+	if err != nil {
+		return fmt.Errorf("error getting %s: %w", c.outParam, err)
+	}
+
+	if columns, err := cfg.As[[]string](c.Arg("csv-columns")); err == nil && len(columns) > 0 {
+		c.columns = columns
+	}
+
+	c.escape, err = cfg.As[bool](c.Arg("csv-escape-formulas"))
+	if err != nil {
+		c.escape = true
+	}
+
+	encryption, err := encryptionFromArgs(c)
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("creating CSV output file", "filename", filename)
+	c.file, err = createOutputFile(filename, encryption)
+	if err != nil {
+		return fmt.Errorf("error creating CSV: %w", err)
+	}
+
+	c.writer = csv.NewWriter(c.file)
+	c.writer.Comma = c.delimiter
+
+	if c.columns != nil {
+		return c.writeRow(c.columns)
+	}
+	return nil
+}
+
+func (c *CSVOutputter) TargetFiles() []string {
+	filename, err := cfg.As[string](c.Arg(c.outParam))
+	if err != nil {
+		return nil
+	}
+	return []string{filename}
+}
+
+func (c *CSVOutputter) Output(val any) error {
+	cells := csvCells(val)
+
+	if c.columns == nil {
+		c.columns = csvColumnNames(val, cells)
+		if err := c.writeRow(c.columns); err != nil {
+			return err
+		}
+	}
+
+	row := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		row[i] = cells[column]
+	}
+	return c.writeRow(row)
+}
+
+func (c *CSVOutputter) writeRow(row []string) error {
+	if c.escape {
+		for i, cell := range row {
+			row[i] = escapeFormula(cell)
+		}
+	}
+
+	if err := c.writer.Write(row); err != nil {
+		return fmt.Errorf("error writing CSV row: %w", err)
+	}
+
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *CSVOutputter) Complete() error {
+	if c.file == nil {
+		return nil
+	}
+
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		c.file.Close()
+		return fmt.Errorf("error flushing CSV: %w", err)
+	}
+	return c.file.Close()
+}
+
+// escapeFormula defuses cells a spreadsheet would evaluate as formulas. Numbers such as "-1"
+// are left alone.
+func escapeFormula(cell string) string {
+	if _, err := strconv.ParseFloat(cell, 64); err == nil {
+		return cell
+	}
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// csvColumnNames returns the columns of val in order: struct fields in declaration order, or
+// sorted map keys.
+func csvColumnNames(val any, cells map[string]string) []string {
+	t := reflect.TypeOf(val)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t != nil && t.Kind() == reflect.Struct && !isCSVLeaf(t) {
+		names := []string{}
+		for _, column := range csvColumnsOf(t, "", nil) {
+			names = append(names, column.name)
+		}
+		return names
+	}
+
+	names := []string{}
+	for name := range cells {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// csvCells maps each column name of val to its formatted value.
+func csvCells(val any) map[string]string {
+	v := reflect.ValueOf(val)
+	for v.IsValid() && v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	cells := make(map[string]string)
+	switch {
+	case !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()):
+		cells["value"] = ""
+	case v.Kind() == reflect.Struct && !isCSVLeaf(v.Type()):
+		for _, column := range csvColumnsOf(v.Type(), "", nil) {
+			cells[column.name] = formatCSVValue(fieldByIndex(v, column.index))
+		}
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		for _, key := range v.MapKeys() {
+			cells[key.String()] = formatCSVValue(v.MapIndex(key))
+		}
+	default:
+		cells["value"] = formatCSVValue(v)
+	}
+	return cells
+}
+
+// csvColumnsOf flattens the exported fields of struct type t into columns. seen guards
+// against recursive types.
+func csvColumnsOf(t reflect.Type, prefix string, seen []reflect.Type) []csvColumn {
+	if slices.Contains(seen, t) {
+		return nil
+	}
+	seen = append(seen, t)
+
+	columns := []csvColumn{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, skip := csvFieldName(field)
+		if skip {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		nested := fieldType.Kind() == reflect.Struct && !isCSVLeaf(fieldType)
+		if nested {
+			nestedPrefix := prefix + name + "."
+			if field.Anonymous && !hasCSVTag(field) {
+				nestedPrefix = prefix
+			}
+			for _, column := range csvColumnsOf(fieldType, nestedPrefix, seen) {
+				column.index = append([]int{i}, column.index...)
+				columns = append(columns, column)
+			}
+			continue
+		}
+
+		columns = append(columns, csvColumn{name: prefix + name, index: []int{i}})
+	}
+	return columns
+}
+
+func csvFieldName(field reflect.StructField) (string, bool) {
+	for _, tag := range []string{"janus", "json"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return "", true
+		}
+		if name != "" {
+			return name, false
+		}
+	}
+	return field.Name, false
+}
+
+func hasCSVTag(field reflect.StructField) bool {
+	name, _ := csvFieldName(field)
+	return name != field.Name
+}
+
+// isCSVLeaf reports whether a struct type formats itself, e.g. time.Time, and so should be
+// written as a single cell rather than flattened.
+func isCSVLeaf(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	for _, iface := range []reflect.Type{
+		reflect.TypeOf((*fmt.Stringer)(nil)).Elem(),
+		reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem(),
+		reflect.TypeOf((*json.Marshaler)(nil)).Elem(),
+	} {
+		if t.Implements(iface) || ptr.Implements(iface) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex, returning an invalid value instead of panicking
+// when the path passes through a nil pointer.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, fieldIndex := range index {
+		if i > 0 {
+			for v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return reflect.Value{}
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(fieldIndex)
+	}
+	return v
+}
+
+func formatCSVValue(v reflect.Value) string {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+
+	if v.CanInterface() {
+		switch value := v.Interface().(type) {
+		case fmt.Stringer:
+			return value.String()
+		case encoding.TextMarshaler:
+			text, err := value.MarshalText()
+			if err == nil {
+				return string(text)
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.IsNil() {
+			return ""
+		}
+		raw, err := json.Marshal(v.Interface())
+		if err != nil {
+			return fmt.Sprintf("%v", v.Interface())
+		}
+		return string(raw)
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
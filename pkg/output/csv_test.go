@@ -0,0 +1,139 @@
+package output_test
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/output"
+	"github.com/praetorian-inc/janus-framework/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type csvTestItem struct {
+	Name     string `janus:"name"`
+	Count    int    `json:"count"`
+	Secret   string `janus:"-"`
+	Plain    bool
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Location *csvTestLocation  `json:"location"`
+}
+
+type csvTestLocation struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+func readCSV(t *testing.T, filename string, delimiter rune) [][]string {
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	require.NoError(t, err)
+	return records
+}
+
+func TestCSVOutputter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.csv")
+	outputter := output.NewCSVOutputter(cfg.WithArg("csvoutfile", filename))
+	require.NoError(t, outputter.Initialize())
+
+	o := outputter.(*output.CSVOutputter)
+	require.NoError(t, o.Output(csvTestItem{
+		Name:     "a, \"quoted\"\nname",
+		Count:    -1,
+		Secret:   "hidden",
+		Plain:    true,
+		Tags:     []string{"x", "y"},
+		Location: &csvTestLocation{File: "main.go", Line: 10},
+	}))
+
+	records := readCSV(t, filename, ',')
+	require.Len(t, records, 2, "rows should be written as items arrive")
+
+	require.NoError(t, o.Output(&csvTestItem{Name: "=HYPERLINK(\"evil\")"}))
+	require.NoError(t, o.Complete())
+
+	records = readCSV(t, filename, ',')
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{"name", "count", "Plain", "tags", "labels", "location.file", "location.line"}, records[0])
+	assert.Equal(t, []string{"a, \"quoted\"\nname", "-1", "true", `["x","y"]`, "", "main.go", "10"}, records[1])
+	assert.Equal(t, []string{"'=HYPERLINK(\"evil\")", "0", "false", "", "", "", ""}, records[2])
+}
+
+func TestCSVOutputter_EscapesFormulas(t *testing.T) {
+	tests := []struct {
+		cell     string
+		expected string
+	}{
+		{"=1+1", "'=1+1"},
+		{"+1+1", "'+1+1"},
+		{"-1+1", "'-1+1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1+1", "'\t=1+1"},
+		{"\r=1+1", "'\r=1+1"},
+		{"-1", "-1"},
+		{"+1.5", "+1.5"},
+		{"plain", "plain"},
+	}
+
+	filename := filepath.Join(t.TempDir(), "test.csv")
+	outputter := output.NewCSVOutputter(cfg.WithArg("csvoutfile", filename))
+	require.NoError(t, outputter.Initialize())
+
+	o := outputter.(*output.CSVOutputter)
+	for _, tt := range tests {
+		require.NoError(t, o.Output(map[string]any{"cell": tt.cell}))
+	}
+	require.NoError(t, o.Complete())
+
+	records := readCSV(t, filename, ',')
+	require.Len(t, records, len(tests)+1)
+	for i, tt := range tests {
+		assert.Equal(t, tt.expected, records[i+1][0], "cell %q", tt.cell)
+	}
+}
+
+func TestCSVOutputter_Columns(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.tsv")
+	outputter := output.NewTSVOutputter(
+		cfg.WithArg("tsvoutfile", filename),
+		cfg.WithArg("csv-columns", []string{"rule_name", "provenance.repo_path", "snippet.matching", "missing"}),
+	)
+	require.NoError(t, outputter.Initialize())
+
+	o := outputter.(*output.CSVOutputter)
+	require.NoError(t, o.Output(types.NPFinding{
+		RuleName:   "AWS API Key",
+		Provenance: types.NPProvenance{RepoPath: "https://github.com/org/repo"},
+		Snippet:    types.NPSnippet{Matching: "AKIA\tEXAMPLE"},
+	}))
+	require.NoError(t, o.Complete())
+
+	records := readCSV(t, filename, '\t')
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"rule_name", "provenance.repo_path", "snippet.matching", "missing"}, records[0])
+	assert.Equal(t, []string{"AWS API Key", "https://github.com/org/repo", "AKIA\tEXAMPLE", ""}, records[1])
+}
+
+func TestCSVOutputter_NonStruct(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.csv")
+	outputter := output.NewCSVOutputter(cfg.WithArg("csvoutfile", filename), cfg.WithArg("csv-escape-formulas", false))
+	require.NoError(t, outputter.Initialize())
+
+	o := outputter.(*output.CSVOutputter)
+	require.NoError(t, o.Output(map[string]any{"b": 2, "a": "=1"}))
+	require.NoError(t, o.Output(map[string]any{"a": "x", "c": 3}))
+	require.NoError(t, o.Complete())
+
+	records := readCSV(t, filename, ',')
+	assert.Equal(t, [][]string{{"a", "b"}, {"=1", "2"}, {"x", ""}}, records)
+}
@@ -0,0 +1,502 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+)
+
+// Encrypted files start with encryptionMagic, followed by a header giving how the file key is
+// derived, then a stream of AES-256-GCM sealed chunks. Each chunk is prefixed by its sealed
+// length and sealed with a nonce made of its index and a flag marking the final chunk, so
+// chunks can't be reordered, dropped or truncated without decryption failing.
+//
+// Appending to an encrypted file appends a new stream; readers decrypt the streams in turn.
+const (
+	encryptionMagic         = "janus-encrypted/v1\n"
+	encryptionPassphrase    = byte(1)
+	encryptionRecipient     = byte(2)
+	encryptionChunkSize     = 64 * 1024
+	encryptionIterations    = 600_000
+	encryptionMaxIterations = 10_000_000
+	encryptionSaltSize      = 16
+	encryptionKeySize       = 32
+	encryptionLastChunk     = byte(1)
+	encryptionKeyInfo       = "janus-encrypted/v1 x25519"
+)
+
+var ErrDecryption = errors.New("error decrypting: wrong key or corrupted file")
+
+// EncryptionOptions configures how files are encrypted: with a key derived from Passphrase,
+// or to Recipient, a base64 X25519 public key from GenerateRecipientKey. Exactly one must be
+// set.
+type EncryptionOptions struct {
+	Passphrase string
+	Recipient  string
+}
+
+// DecryptionOptions holds what files are decrypted with: the Passphrase they were encrypted
+// with, or Identity, the base64 X25519 private key matching their recipient.
+type DecryptionOptions struct {
+	Passphrase string
+	Identity   string
+}
+
+// GenerateRecipientKey returns a new base64 X25519 key pair. Files are encrypted to the public
+// key and decrypted with the private key, so only the holder of the private key can read them.
+func GenerateRecipientKey() (identity string, recipient string, err error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("error generating key: %w", err)
+	}
+
+	identity = base64.StdEncoding.EncodeToString(key.Bytes())
+	recipient = base64.StdEncoding.EncodeToString(key.PublicKey().Bytes())
+	return identity, recipient, nil
+}
+
+// EncryptWriter encrypts everything written to it. Close must be called to write the final
+// chunk; it does not close the underlying writer.
+type EncryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+	closed  bool
+}
+
+// NewEncryptWriter writes the header of a new encrypted stream to w and returns a writer
+// encrypting into it.
+func NewEncryptWriter(w io.Writer, opts EncryptionOptions) (*EncryptWriter, error) {
+	header := bytes.NewBufferString(encryptionMagic)
+
+	var key []byte
+	switch {
+	case opts.Passphrase != "" && opts.Recipient != "":
+		return nil, fmt.Errorf("cannot encrypt with both a passphrase and a recipient")
+	case opts.Passphrase != "":
+		salt := make([]byte, encryptionSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("error generating salt: %w", err)
+		}
+
+		var err error
+		key, err = pbkdf2.Key(sha256.New, opts.Passphrase, salt, encryptionIterations, encryptionKeySize)
+		if err != nil {
+			return nil, fmt.Errorf("error deriving key: %w", err)
+		}
+
+		header.WriteByte(encryptionPassphrase)
+		header.Write(salt)
+		binary.Write(header, binary.BigEndian, uint32(encryptionIterations))
+	case opts.Recipient != "":
+		recipient, err := parseRecipient(opts.Recipient)
+		if err != nil {
+			return nil, err
+		}
+
+		ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("error generating ephemeral key: %w", err)
+		}
+
+		key, err = recipientKey(ephemeral, recipient, ephemeral.PublicKey())
+		if err != nil {
+			return nil, err
+		}
+
+		header.WriteByte(encryptionRecipient)
+		header.Write(ephemeral.PublicKey().Bytes())
+	default:
+		return nil, fmt.Errorf("no passphrase or recipient to encrypt with")
+	}
+
+	aead, err := newEncryptionAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return nil, fmt.Errorf("error writing encryption header: %w", err)
+	}
+	return &EncryptWriter{w: w, aead: aead}, nil
+}
+
+func (e *EncryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, fmt.Errorf("write to closed EncryptWriter")
+	}
+
+	written := len(p)
+	for len(p) > 0 {
+		n := min(len(p), encryptionChunkSize-len(e.buf))
+		e.buf = append(e.buf, p[:n]...)
+		p = p[n:]
+
+		if len(e.buf) == encryptionChunkSize {
+			if err := e.writeChunk(false); err != nil {
+				return written - len(p), err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Flush encrypts and writes whatever has been written since the last chunk, so it is
+// recoverable even if the stream is never closed.
+func (e *EncryptWriter) Flush() error {
+	if e.closed || len(e.buf) == 0 {
+		return nil
+	}
+	return e.writeChunk(false)
+}
+
+// Close writes the final chunk, which marks the end of the stream.
+func (e *EncryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.writeChunk(true)
+}
+
+func (e *EncryptWriter) writeChunk(last bool) error {
+	sealed := e.aead.Seal(nil, chunkNonce(e.counter, last), e.buf, nil)
+	e.counter++
+	e.buf = e.buf[:0]
+
+	record := binary.BigEndian.AppendUint32(nil, uint32(len(sealed)))
+	if _, err := e.w.Write(append(record, sealed...)); err != nil {
+		return fmt.Errorf("error writing encrypted chunk: %w", err)
+	}
+	return nil
+}
+
+type decryptReader struct {
+	r       *bufio.Reader
+	opts    DecryptionOptions
+	aead    cipher.AEAD
+	counter uint64
+	buf     []byte
+	done    bool // the current stream's final chunk has been read
+}
+
+// NewDecryptReader returns a reader decrypting the encrypted streams read from r.
+func NewDecryptReader(r io.Reader, opts DecryptionOptions) (io.Reader, error) {
+	d := &decryptReader{r: bufio.NewReader(r), opts: opts}
+	if err := d.readHeader(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// DecryptFile reads and decrypts the file at path.
+func DecryptFile(path string, opts DecryptionOptions) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r, err := NewDecryptReader(file, opts)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// IsEncrypted reports whether the file at path was encrypted by an EncryptWriter.
+func IsEncrypted(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	magic := make([]byte, len(encryptionMagic))
+	if _, err := io.ReadFull(file, magic); err != nil {
+		return false, nil
+	}
+	return string(magic) == encryptionMagic, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.done {
+			if _, err := d.r.Peek(1); err == io.EOF {
+				return 0, io.EOF
+			}
+			if err := d.readHeader(); err != nil {
+				return 0, err
+			}
+		}
+
+		if err := d.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+func (d *decryptReader) readHeader() error {
+	magic := make([]byte, len(encryptionMagic))
+	if _, err := io.ReadFull(d.r, magic); err != nil || string(magic) != encryptionMagic {
+		return fmt.Errorf("not an encrypted file")
+	}
+
+	mode, err := d.r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("error reading encryption header: %w", err)
+	}
+
+	var key []byte
+	switch mode {
+	case encryptionPassphrase:
+		if d.opts.Passphrase == "" {
+			return fmt.Errorf("file is encrypted with a passphrase, but none was given")
+		}
+
+		salt := make([]byte, encryptionSaltSize)
+		var iterations uint32
+		if _, err := io.ReadFull(d.r, salt); err != nil {
+			return fmt.Errorf("error reading encryption header: %w", err)
+		}
+		if err := binary.Read(d.r, binary.BigEndian, &iterations); err != nil {
+			return fmt.Errorf("error reading encryption header: %w", err)
+		}
+		if iterations == 0 || iterations > encryptionMaxIterations {
+			return fmt.Errorf("invalid key derivation iterations %d", iterations)
+		}
+
+		key, err = pbkdf2.Key(sha256.New, d.opts.Passphrase, salt, int(iterations), encryptionKeySize)
+		if err != nil {
+			return fmt.Errorf("error deriving key: %w", err)
+		}
+	case encryptionRecipient:
+		if d.opts.Identity == "" {
+			return fmt.Errorf("file is encrypted to a recipient key, but no identity was given")
+		}
+
+		identity, err := parseIdentity(d.opts.Identity)
+		if err != nil {
+			return err
+		}
+
+		raw := make([]byte, 32)
+		if _, err := io.ReadFull(d.r, raw); err != nil {
+			return fmt.Errorf("error reading encryption header: %w", err)
+		}
+		ephemeral, err := ecdh.X25519().NewPublicKey(raw)
+		if err != nil {
+			return fmt.Errorf("invalid ephemeral key: %w", err)
+		}
+
+		key, err = recipientKey(identity, ephemeral, ephemeral)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown encryption mode %d", mode)
+	}
+
+	d.aead, err = newEncryptionAEAD(key)
+	if err != nil {
+		return err
+	}
+	d.counter = 0
+	d.done = false
+	return nil
+}
+
+func (d *decryptReader) readChunk() error {
+	var length uint32
+	if err := binary.Read(d.r, binary.BigEndian, &length); err != nil {
+		return fmt.Errorf("encrypted file is truncated: %w", io.ErrUnexpectedEOF)
+	}
+	if length > encryptionChunkSize+uint32(d.aead.Overhead()) {
+		return ErrDecryption
+	}
+
+	sealed := make([]byte, length)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return fmt.Errorf("encrypted file is truncated: %w", io.ErrUnexpectedEOF)
+	}
+
+	plain, err := d.aead.Open(nil, chunkNonce(d.counter, false), sealed, nil)
+	if err != nil {
+		plain, err = d.aead.Open(nil, chunkNonce(d.counter, true), sealed, nil)
+		if err != nil {
+			return ErrDecryption
+		}
+		d.done = true
+	}
+
+	d.counter++
+	d.buf = plain
+	return nil
+}
+
+func chunkNonce(counter uint64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if last {
+		nonce[11] = encryptionLastChunk
+	}
+	return nonce
+}
+
+func newEncryptionAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// recipientKey derives the file key from the X25519 exchange between private and public,
+// bound to the stream's ephemeral public key.
+func recipientKey(private *ecdh.PrivateKey, public *ecdh.PublicKey, ephemeral *ecdh.PublicKey) ([]byte, error) {
+	shared, err := private.ECDH(public)
+	if err != nil {
+		return nil, fmt.Errorf("error exchanging keys: %w", err)
+	}
+	return hkdf.Key(sha256.New, shared, ephemeral.Bytes(), encryptionKeyInfo, encryptionKeySize)
+}
+
+func parseRecipient(encoded string) (*ecdh.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X25519 public key: %w", err)
+	}
+
+	key, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X25519 public key: %w", err)
+	}
+	return key, nil
+}
+
+func parseIdentity(encoded string) (*ecdh.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X25519 private key: %w", err)
+	}
+
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X25519 private key: %w", err)
+	}
+	return key, nil
+}
+
+// encryptionParams are the params of outputters that can encrypt the files they write.
+func encryptionParams() []cfg.Param {
+	return []cfg.Param{
+		cfg.NewParam[string]("encrypt-passphrase", "encrypt written files with a key derived from this passphrase").AsSensitive(),
+		cfg.NewParam[string]("encrypt-recipient", "encrypt written files to this base64 X25519 public key"),
+	}
+}
+
+// encryptionFromArgs returns the encryption configured by an outputter's encryption params,
+// or nil if its files aren't encrypted.
+func encryptionFromArgs(args interface{ Arg(string) any }) (*EncryptionOptions, error) {
+	passphrase, _ := cfg.As[string](args.Arg("encrypt-passphrase"))
+	recipient, _ := cfg.As[string](args.Arg("encrypt-recipient"))
+
+	if passphrase == "" && recipient == "" {
+		return nil, nil
+	}
+	if passphrase != "" && recipient != "" {
+		return nil, fmt.Errorf("only one of encrypt-passphrase and encrypt-recipient can be set")
+	}
+	if recipient != "" {
+		if _, err := parseRecipient(recipient); err != nil {
+			return nil, fmt.Errorf("invalid encrypt-recipient: %w", err)
+		}
+	}
+	return &EncryptionOptions{Passphrase: passphrase, Recipient: recipient}, nil
+}
+
+// outputFile is a file written by an outputter, encrypting its contents when encryption is
+// configured.
+type outputFile struct {
+	file *os.File
+	enc  *EncryptWriter
+	w    io.Writer
+}
+
+func openOutputFile(filename string, flags int, encryption *EncryptionOptions) (*outputFile, error) {
+	perm := os.FileMode(0666)
+	if encryption != nil {
+		perm = 0600
+	}
+
+	file, err := os.OpenFile(filename, flags, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &outputFile{file: file, w: file}
+	if encryption == nil {
+		return f, nil
+	}
+
+	f.enc, err = NewEncryptWriter(file, *encryption)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	f.w = f.enc
+	return f, nil
+}
+
+func createOutputFile(filename string, encryption *EncryptionOptions) (*outputFile, error) {
+	return openOutputFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, encryption)
+}
+
+func (f *outputFile) Write(p []byte) (int, error) {
+	return f.w.Write(p)
+}
+
+// Flush writes a partial encrypted chunk to the file, without syncing it to disk.
+func (f *outputFile) Flush() error {
+	if f.enc != nil {
+		return f.enc.Flush()
+	}
+	return nil
+}
+
+// Sync flushes written data to disk, including a partial encrypted chunk.
+func (f *outputFile) Sync() error {
+	if err := f.Flush(); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+func (f *outputFile) Close() error {
+	if f.enc != nil {
+		if err := f.enc.Close(); err != nil {
+			f.file.Close()
+			return err
+		}
+	}
+	return f.file.Close()
+}
@@ -0,0 +1,228 @@
+package output_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/links"
+	"github.com/praetorian-inc/janus-framework/pkg/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encryptToBuffer(t *testing.T, opts output.EncryptionOptions, plaintext string) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	w, err := output.NewEncryptWriter(buf, opts)
+	require.NoError(t, err)
+
+	_, err = io.WriteString(w, plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf
+}
+
+func decrypt(t *testing.T, r io.Reader, opts output.DecryptionOptions) (string, error) {
+	dr, err := output.NewDecryptReader(r, opts)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := io.ReadAll(dr)
+	return string(plaintext), err
+}
+
+func TestEncryption_RoundTrip(t *testing.T) {
+	identity, recipient, err := output.GenerateRecipientKey()
+	require.NoError(t, err)
+
+	large := strings.Repeat("AKIAEXAMPLEKEY ", 10_000) // spans several chunks
+
+	tests := []struct {
+		name    string
+		encrypt output.EncryptionOptions
+		decrypt output.DecryptionOptions
+	}{
+		{"passphrase", output.EncryptionOptions{Passphrase: "hunter2"}, output.DecryptionOptions{Passphrase: "hunter2"}},
+		{"recipient", output.EncryptionOptions{Recipient: recipient}, output.DecryptionOptions{Identity: identity}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, plaintext := range []string{"", "secret", large} {
+				buf := encryptToBuffer(t, tt.encrypt, plaintext)
+				assert.NotContains(t, buf.String(), "secret")
+				assert.NotContains(t, buf.String(), "AKIA")
+
+				decrypted, err := decrypt(t, buf, tt.decrypt)
+				require.NoError(t, err)
+				assert.Equal(t, plaintext, decrypted)
+			}
+		})
+	}
+}
+
+func TestEncryption_Rejects(t *testing.T) {
+	identity, _, err := output.GenerateRecipientKey()
+	require.NoError(t, err)
+
+	encrypted := encryptToBuffer(t, output.EncryptionOptions{Passphrase: "hunter2"}, strings.Repeat("x", 100_000)).Bytes()
+
+	_, err = decrypt(t, bytes.NewReader(encrypted), output.DecryptionOptions{Passphrase: "wrong"})
+	assert.ErrorIs(t, err, output.ErrDecryption)
+
+	_, err = decrypt(t, bytes.NewReader(encrypted), output.DecryptionOptions{Identity: identity})
+	assert.ErrorContains(t, err, "encrypted with a passphrase")
+
+	_, err = decrypt(t, bytes.NewReader(encrypted[:len(encrypted)-100]), output.DecryptionOptions{Passphrase: "hunter2"})
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	tampered := bytes.Clone(encrypted)
+	tampered[len(tampered)-1] ^= 1
+	_, err = decrypt(t, bytes.NewReader(tampered), output.DecryptionOptions{Passphrase: "hunter2"})
+	assert.ErrorIs(t, err, output.ErrDecryption)
+
+	_, err = decrypt(t, strings.NewReader("plaintext"), output.DecryptionOptions{Passphrase: "hunter2"})
+	assert.ErrorContains(t, err, "not an encrypted file")
+}
+
+func TestEncryption_Outputters(t *testing.T) {
+	dir := t.TempDir()
+	passphrase := cfg.WithArg("encrypt-passphrase", "hunter2")
+	item := map[string]any{"secret": "ghp_example"}
+
+	tests := []struct {
+		outputter func(...cfg.Config) chain.Outputter
+		file      string
+		param     string
+		expected  string
+	}{
+		{output.NewJSONOutputter, "out.json", "jsonoutfile", `[{"secret":"ghp_example"}]` + "\n"},
+		{func(c ...cfg.Config) chain.Outputter {
+			return output.NewJSONLOutputter(append(c, cfg.WithArg("jsonl-type-field", ""))...)
+		}, "out.jsonl", "jsonloutfile", `{"secret":"ghp_example"}` + "\n"},
+		{output.NewCSVOutputter, "out.csv", "csvoutfile", "secret\nghp_example\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			filename := filepath.Join(dir, tt.file)
+			outputter := tt.outputter(passphrase, cfg.WithArg(tt.param, filename))
+			require.NoError(t, outputter.Initialize())
+			require.NoError(t, chain.Output(outputter, item))
+			require.NoError(t, outputter.Complete())
+
+			raw, err := os.ReadFile(filename)
+			require.NoError(t, err)
+			assert.NotContains(t, string(raw), "ghp_example")
+
+			encrypted, err := output.IsEncrypted(filename)
+			require.NoError(t, err)
+			assert.True(t, encrypted)
+
+			info, err := os.Stat(filename)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+			plaintext, err := output.DecryptFile(filename, output.DecryptionOptions{Passphrase: "hunter2"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(plaintext))
+		})
+	}
+}
+
+func TestEncryption_MarkdownRecipient(t *testing.T) {
+	identity, recipient, err := output.GenerateRecipientKey()
+	require.NoError(t, err)
+
+	filename := filepath.Join(t.TempDir(), "out.md")
+	outputter := output.NewMarkdownOutputter(cfg.WithArg("mdoutfile", filename), cfg.WithArg("encrypt-recipient", recipient))
+	require.NoError(t, outputter.Initialize())
+	require.NoError(t, outputter.(*output.MarkdownOutputter).Output(&mockMarkdownable{column: "secret", value: "ghp_example"}))
+	require.NoError(t, outputter.Complete())
+
+	plaintext, err := output.DecryptFile(filename, output.DecryptionOptions{Identity: identity})
+	require.NoError(t, err)
+	assert.Equal(t, "| secret      |\n| ----------- |\n| ghp_example |\n", string(plaintext))
+}
+
+func TestEncryption_JSONLAppendAndGzip(t *testing.T) {
+	identity, recipient, err := output.GenerateRecipientKey()
+	require.NoError(t, err)
+	filename := filepath.Join(t.TempDir(), "out.jsonl.gz")
+
+	for _, value := range []string{"first", "second"} {
+		outputter := output.NewJSONLOutputter(
+			cfg.WithArg("jsonloutfile", filename),
+			cfg.WithArg("jsonl-append", true),
+			cfg.WithArg("encrypt-recipient", recipient),
+		)
+		require.NoError(t, outputter.Initialize())
+		require.NoError(t, outputter.(*output.JSONLOutputter).Output(value))
+		require.NoError(t, outputter.Complete())
+	}
+
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer file.Close()
+
+	r, err := output.NewDecryptReader(file, output.DecryptionOptions{Identity: identity})
+	require.NoError(t, err)
+
+	values := []any{}
+	for item, err := range output.ReadJSONL(r, output.DefaultJSONLTypeField) {
+		require.NoError(t, err)
+		values = append(values, item)
+	}
+	assert.Equal(t, []any{"first", "second"}, values)
+}
+
+func TestEncryption_InvalidParams(t *testing.T) {
+	outputter := output.NewJSONOutputter(
+		cfg.WithArg("jsonoutfile", filepath.Join(t.TempDir(), "out.json")),
+		cfg.WithArg("encrypt-passphrase", "hunter2"),
+		cfg.WithArg("encrypt-recipient", "not a key"),
+	)
+	assert.ErrorContains(t, outputter.Initialize(), "only one of encrypt-passphrase and encrypt-recipient")
+
+	outputter = output.NewJSONOutputter(
+		cfg.WithArg("jsonoutfile", filepath.Join(t.TempDir(), "out.json")),
+		cfg.WithArg("encrypt-recipient", "not a key"),
+	)
+	assert.ErrorContains(t, outputter.Initialize(), "invalid encrypt-recipient")
+}
+
+func TestEncryption_PassphraseRedactedFromPlan(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.json")
+	planWriter := &bytes.Buffer{}
+
+	module := chain.NewModule(
+		cfg.NewMetadata(
+			"encrypt-plan",
+			"encrypt plan test",
+		).WithChainInputParam("strings"),
+	).WithLinks(
+		func(configs ...cfg.Config) chain.Link {
+			return links.FromWrapper(strings.ToUpper, configs...)
+		},
+	).WithInputParam(
+		cfg.NewParam[[]string]("strings", "strings to process"),
+	).WithOutputters(
+		output.NewJSONOutputter,
+	).WithDryRun(planWriter)
+
+	args := cfg.WithCLIArgs([]string{"-strings", "a", "-jsonoutfile", filename, "-encrypt-passphrase", "hunter2"})
+	require.NoError(t, module.Run(args))
+	assert.Contains(t, planWriter.String(), "encrypt-passphrase (string) = "+cfg.RedactedValue)
+	assert.NotContains(t, planWriter.String(), "hunter2")
+	assert.NoFileExists(t, filename)
+
+	plan, err := module.Plan(args)
+	require.NoError(t, err)
+	assert.NotContains(t, fmt.Sprintf("%+v", plan), "hunter2")
+}
@@ -0,0 +1,361 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/types"
+)
+
+const htmlMaxCellWidth = 200
+
+// HTMLOutputter writes a single self-contained HTML report: no external CSS, scripts or
+// fonts, so it can be opened offline or attached to an email.
+//
+// Items are grouped into one sortable, filterable table per type, using the same columns as
+// CSVOutputter. Every row has a collapsible view of the full item, and NoseyParker findings
+// show their snippet with the match highlighted. A summary lists item counts per type, the
+// run's params with sensitive values redacted, and the run's duration.
+//
+// The report is rendered with html/template. Any of the templates "report", "style",
+// "script", "summary", "section" and "detail" can be replaced by defining it in the file
+// named by the html-template param.
+type HTMLOutputter struct {
+	*chain.BaseOutputter
+	outfile  string
+	started  time.Time
+	sections map[string]*htmlSection
+	order    []string
+	total    int
+}
+
+type htmlReport struct {
+	Title     string
+	Generated time.Time
+	Duration  time.Duration
+	Total     int
+	Params    []htmlParam
+	Sections  []*htmlSection
+}
+
+type htmlParam struct {
+	Name  string
+	Value string
+}
+
+type htmlSection struct {
+	Type    string
+	ID      string
+	Columns []string
+	Rows    []htmlRow
+}
+
+type htmlRow struct {
+	Cells   []string
+	Detail  string
+	Snippet *types.NPSnippet
+}
+
+func NewHTMLOutputter(configs ...cfg.Config) chain.Outputter {
+	h := &HTMLOutputter{sections: make(map[string]*htmlSection)}
+	h.BaseOutputter = chain.NewBaseOutputter(h, configs...)
+	return h
+}
+
+func (h *HTMLOutputter) Params() []cfg.Param {
+	return []cfg.Param{
+		cfg.NewParam[string]("htmloutfile", "the file to write the HTML report to").WithDefault("out.html"),
+		cfg.NewParam[string]("html-title", "the title of the HTML report").WithDefault("Janus Report"),
+		cfg.NewParam[string]("html-template", "a file of html/template definitions overriding the report's templates"),
+		cfg.NewParam[[]string]("html-redact", "additional params whose values are redacted from the report"),
+	}
+}
+
+func (h *HTMLOutputter) Initialize() error {
+	outfile, err := cfg.As[string](h.Arg("htmloutfile"))
+	if err != nil {
+		return fmt.Errorf("error getting htmloutfile: %w", err)
+	}
+	h.outfile = outfile
+	h.started = time.Now()
+
+	if _, err := h.template(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *HTMLOutputter) TargetFiles() []string {
+	outfile, err := cfg.As[string](h.Arg("htmloutfile"))
+	if err != nil {
+		return nil
+	}
+	return []string{outfile}
+}
+
+func (h *HTMLOutputter) Output(val any) error {
+	typeName := JSONLTypeName(val)
+	section, ok := h.sections[typeName]
+	if !ok {
+		section = &htmlSection{Type: typeName, ID: fmt.Sprintf("section-%d", len(h.order))}
+		h.sections[typeName] = section
+		h.order = append(h.order, typeName)
+	}
+
+	cells := csvCells(val)
+	for _, column := range csvColumnNames(val, cells) {
+		if !slices.Contains(section.Columns, column) {
+			section.Columns = append(section.Columns, column)
+		}
+	}
+
+	detail, err := json.MarshalIndent(val, "", "  ")
+	if err != nil {
+		detail = []byte(fmt.Sprintf("%+v", val))
+	}
+
+	row := htmlRow{Detail: string(detail), Snippet: htmlSnippetOf(val)}
+	for _, column := range section.Columns {
+		row.Cells = append(row.Cells, truncateCell(cells[column]))
+	}
+
+	section.Rows = append(section.Rows, row)
+	h.total++
+	return nil
+}
+
+func (h *HTMLOutputter) Complete() error {
+	tmpl, err := h.template()
+	if err != nil {
+		return err
+	}
+
+	title, err := cfg.As[string](h.Arg("html-title"))
+	if err != nil {
+		title = "Janus Report"
+	}
+
+	report := htmlReport{
+		Title:     title,
+		Generated: time.Now(),
+		Duration:  time.Since(h.started).Round(time.Millisecond),
+		Total:     h.total,
+		Params:    h.runParams(),
+	}
+
+	for _, typeName := range h.order {
+		section := h.sections[typeName]
+		for i := range section.Rows {
+			for len(section.Rows[i].Cells) < len(section.Columns) {
+				section.Rows[i].Cells = append(section.Rows[i].Cells, "")
+			}
+		}
+		report.Sections = append(report.Sections, section)
+	}
+
+	slog.Debug("creating HTML output file", "filename", h.outfile)
+	writer, err := os.Create(h.outfile)
+	if err != nil {
+		return fmt.Errorf("error creating HTML report: %w", err)
+	}
+	defer writer.Close()
+
+	return h.render(writer, tmpl, report)
+}
+
+func (h *HTMLOutputter) render(w io.Writer, tmpl *template.Template, report htmlReport) error {
+	if err := tmpl.ExecuteTemplate(w, "report", report); err != nil {
+		return fmt.Errorf("error rendering HTML report: %w", err)
+	}
+	return nil
+}
+
+func (h *HTMLOutputter) template() (*template.Template, error) {
+	tmpl, err := template.New("report").Parse(defaultHTMLTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing default HTML template: %w", err)
+	}
+
+	path, err := cfg.As[string](h.Arg("html-template"))
+	if err != nil || path == "" {
+		return tmpl, nil
+	}
+
+	overrides, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading html-template: %w", err)
+	}
+
+	if tmpl, err = tmpl.Parse(string(overrides)); err != nil {
+		return nil, fmt.Errorf("error parsing html-template %s: %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// runParams lists the args the outputter received, which include every arg of the chain.
+// Values of sensitive params are redacted and values with no useful text form are skipped.
+func (h *HTMLOutputter) runParams() []htmlParam {
+	redact, _ := cfg.As[[]string](h.Arg("html-redact"))
+
+	params := []htmlParam{}
+	for name, value := range h.Args() {
+		if value == nil {
+			continue
+		}
+
+		kind := reflect.TypeOf(value).Kind()
+		if kind == reflect.Func || kind == reflect.Chan || kind == reflect.Interface {
+			continue
+		}
+		if _, ok := value.(io.Writer); ok {
+			continue
+		}
+
+		text := fmt.Sprintf("%v", value)
+		sensitive := cfg.IsSensitiveName(name)
+		if param := h.Param(name); param != nil {
+			sensitive = cfg.IsSensitive(param)
+		}
+		if sensitive || slices.Contains(redact, name) {
+			text = cfg.RedactedValue
+		}
+		params = append(params, htmlParam{Name: name, Value: text})
+	}
+
+	sort.Slice(params, func(i, j int) bool {
+		return params[i].Name < params[j].Name
+	})
+	return params
+}
+
+func htmlSnippetOf(val any) *types.NPSnippet {
+	switch v := val.(type) {
+	case types.NPFinding:
+		return &v.Snippet
+	case *types.NPFinding:
+		return &v.Snippet
+	default:
+		return nil
+	}
+}
+
+// truncateCell shortens cells longer than htmlMaxCellWidth characters, cutting between runes
+// so multibyte characters aren't split.
+func truncateCell(cell string) string {
+	if utf8.RuneCountInString(cell) <= htmlMaxCellWidth {
+		return cell
+	}
+	return string([]rune(cell)[:htmlMaxCellWidth]) + "…"
+}
+
+const defaultHTMLTemplate = `{{define "report"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>{{template "style" .}}</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{template "summary" .}}
+{{range .Sections}}{{template "section" .}}{{end}}
+<script>{{template "script" .}}</script>
+</body>
+</html>
+{{end}}
+
+{{define "style"}}
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+h1 { margin-bottom: 0.2em; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1em; font-size: 0.9em; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: top; word-break: break-word; }
+th { background: #f6f8fa; }
+th.sortable { cursor: pointer; user-select: none; }
+th.sortable::after { content: " \2195"; color: #8c959f; }
+th.asc::after { content: " \2191"; }
+th.desc::after { content: " \2193"; }
+pre { background: #f6f8fa; padding: 8px; overflow-x: auto; white-space: pre-wrap; }
+mark { background: #ffd33d; }
+input.filter { margin: 0.5em 0; padding: 4px; width: 20em; }
+.summary td:first-child { font-weight: bold; width: 15em; }
+{{end}}
+
+{{define "summary"}}
+<section class="summary">
+<h2>Summary</h2>
+<table>
+<tr><td>Generated</td><td>{{.Generated.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+<tr><td>Duration</td><td>{{.Duration}}</td></tr>
+<tr><td>Total items</td><td>{{.Total}}</td></tr>
+{{range .Sections}}<tr><td>{{.Type}}</td><td><a href="#{{.ID}}">{{len .Rows}}</a></td></tr>
+{{end}}</table>
+{{if .Params}}<details>
+<summary>Run parameters</summary>
+<table>
+{{range .Params}}<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>
+</details>{{end}}
+</section>
+{{end}}
+
+{{define "section"}}
+<section id="{{.ID}}">
+<h2>{{.Type}} ({{len .Rows}})</h2>
+<input class="filter" type="search" placeholder="Filter rows" data-table="{{.ID}}-table">
+<table id="{{.ID}}-table">
+<thead><tr>{{range .Columns}}<th class="sortable">{{.}}</th>{{end}}<th>Details</th></tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .Cells}}<td>{{.}}</td>{{end}}<td>{{template "detail" .}}</td></tr>
+{{end}}</tbody>
+</table>
+</section>
+{{end}}
+
+{{define "detail"}}<details><summary>View</summary>
+{{with .Snippet}}<pre>{{.Before}}<mark>{{.Matching}}</mark>{{.After}}</pre>{{end}}
+<pre>{{.Detail}}</pre>
+</details>{{end}}
+
+{{define "script"}}
+document.querySelectorAll("input.filter").forEach(function (input) {
+  input.addEventListener("input", function () {
+    var needle = input.value.toLowerCase();
+    var rows = document.getElementById(input.dataset.table).tBodies[0].rows;
+    for (var i = 0; i < rows.length; i++) {
+      rows[i].style.display = rows[i].textContent.toLowerCase().indexOf(needle) === -1 ? "none" : "";
+    }
+  });
+});
+
+document.querySelectorAll("th.sortable").forEach(function (th) {
+  th.addEventListener("click", function () {
+    var table = th.closest("table");
+    var body = table.tBodies[0];
+    var index = Array.prototype.indexOf.call(th.parentNode.children, th);
+    var asc = !th.classList.contains("asc");
+    table.querySelectorAll("th").forEach(function (h) { h.classList.remove("asc", "desc"); });
+    th.classList.add(asc ? "asc" : "desc");
+
+    var rows = Array.prototype.slice.call(body.rows);
+    rows.sort(function (a, b) {
+      var x = a.cells[index].textContent, y = b.cells[index].textContent;
+      var nx = parseFloat(x), ny = parseFloat(y);
+      var cmp = (!isNaN(nx) && !isNaN(ny)) ? nx - ny : x.localeCompare(y);
+      return asc ? cmp : -cmp;
+    });
+    rows.forEach(function (row) { body.appendChild(row); });
+  });
+});
+{{end}}`
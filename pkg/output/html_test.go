@@ -0,0 +1,133 @@
+package output_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/output"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks/basics"
+	"github.com/praetorian-inc/janus-framework/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLOutputter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "report.html")
+	outputter := output.NewHTMLOutputter(
+		cfg.WithArg("htmloutfile", filename),
+		cfg.WithArg("html-title", "Engagement <Report>"),
+	)
+	require.NoError(t, outputter.Initialize())
+
+	o := outputter.(*output.HTMLOutputter)
+	require.NoError(t, o.Output(types.NPFinding{
+		RuleName: "AWS API Key",
+		Snippet:  types.NPSnippet{Before: "KEY=", Matching: "AKIA<script>", After: ";"},
+	}))
+	require.NoError(t, o.Output(types.ScannableAsset{Target: "example.com", Type: "domain"}))
+	require.NoError(t, o.Output(types.ScannableAsset{Target: "10.0.0.1", Type: "ip", IP: "10.0.0.1"}))
+	require.NoError(t, o.Complete())
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	html := string(content)
+
+	assert.Contains(t, html, "<title>Engagement &lt;Report&gt;</title>")
+	assert.Contains(t, html, "<h2>types.NPFinding (1)</h2>")
+	assert.Contains(t, html, "<h2>types.ScannableAsset (2)</h2>")
+	assert.Contains(t, html, "<td>Total items</td><td>3</td>")
+	assert.Contains(t, html, "<th class=\"sortable\">rule_name</th>")
+	assert.Contains(t, html, "<mark>AKIA&lt;script&gt;</mark>", "snippets should be highlighted and escaped")
+	assert.NotContains(t, html, "AKIA<script>")
+	assert.NotContains(t, html, "<link ", "report should not reference external resources")
+	assert.NotContains(t, html, "src=")
+}
+
+func TestHTMLOutputter_TruncatesCellsByRune(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "report.html")
+	outputter := output.NewHTMLOutputter(cfg.WithArg("htmloutfile", filename))
+	require.NoError(t, outputter.Initialize())
+
+	o := outputter.(*output.HTMLOutputter)
+	require.NoError(t, o.Output(types.ScannableAsset{Target: "a" + strings.Repeat("日本", 150), Type: "domain"}))
+	require.NoError(t, o.Complete())
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	assert.True(t, utf8.Valid(content), "truncation should not split multibyte characters")
+	assert.Contains(t, string(content), "<td>a"+strings.Repeat("日本", 99)+"日…</td>")
+}
+
+func TestHTMLOutputter_RedactsParams(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "report.html")
+
+	c := chain.NewChain(
+		basics.NewParamsLink(),
+	).WithOutputters(
+		output.NewHTMLOutputter(),
+	).WithConfigs(
+		cfg.WithArg("htmloutfile", filename),
+		cfg.WithArg("html-redact", []string{"optional"}),
+		cfg.WithArg("required", "client.example.com"),
+		cfg.WithArg("optional", "hunter2"),
+	)
+
+	c.Send("hello")
+	c.Close()
+	c.Wait()
+	require.NoError(t, c.Error())
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	html := string(content)
+
+	assert.Contains(t, html, "<td>required</td><td>client.example.com</td>")
+	assert.Contains(t, html, "<td>default</td><td>3</td>")
+	assert.Contains(t, html, "<td>optional</td><td>[REDACTED]</td>")
+	assert.NotContains(t, html, "hunter2")
+}
+
+func TestHTMLOutputter_RedactsSensitiveNames(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "report.html")
+	outputter := output.NewHTMLOutputter(cfg.WithArg("htmloutfile", filename))
+
+	o := outputter.(*output.HTMLOutputter)
+	require.NoError(t, o.SetParams(cfg.NewParam[string]("api-token", "token"), cfg.NewParam[string]("aws-secret-key", "key")))
+	require.NoError(t, o.SetArg("api-token", "hunter2"))
+	require.NoError(t, o.SetArg("aws-secret-key", "hunter3"))
+	require.NoError(t, o.Initialize())
+	require.NoError(t, o.Complete())
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	html := string(content)
+
+	assert.Contains(t, html, "<td>api-token</td><td>[REDACTED]</td>")
+	assert.NotContains(t, html, "hunter2")
+	assert.NotContains(t, html, "hunter3")
+}
+
+func TestHTMLOutputter_TemplateOverride(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "report.html")
+	templateFile := filepath.Join(dir, "overrides.tmpl")
+	require.NoError(t, os.WriteFile(templateFile, []byte(`{{define "summary"}}<p class="custom">{{.Total}} findings</p>{{end}}`), 0644))
+
+	outputter := output.NewHTMLOutputter(cfg.WithArg("htmloutfile", filename), cfg.WithArg("html-template", templateFile))
+	require.NoError(t, outputter.Initialize())
+
+	o := outputter.(*output.HTMLOutputter)
+	require.NoError(t, o.Output("plain"))
+	require.NoError(t, o.Complete())
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `<p class="custom">1 findings</p>`)
+	assert.False(t, strings.Contains(string(content), "Run parameters"))
+}
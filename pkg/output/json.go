@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"os"
 	"strings"
 
 	"github.com/praetorian-inc/janus-framework/pkg/chain"
@@ -13,6 +12,7 @@ import (
 
 type JSONOutputter struct {
 	*chain.BaseOutputter
+	file    *outputFile
 	encoder *json.Encoder
 	indent  int
 	output  []any
@@ -36,30 +36,53 @@ func (j *JSONOutputter) Initialize() error {
 	}
 	j.indent = indent
 
+	encryption, err := encryptionFromArgs(j)
+	if err != nil {
+		return err
+	}
+
 	slog.Debug("creating JSON output file", "filename", filename)
-	writer, err := os.Create(filename)
+	j.file, err = createOutputFile(filename, encryption)
 	if err != nil {
 		return fmt.Errorf("error creating JSON: %w", err)
 	}
 
-	j.encoder = json.NewEncoder(writer)
+	j.encoder = json.NewEncoder(j.file)
 	j.encoder.SetIndent("", strings.Repeat(" ", j.indent))
 
 	return nil
 }
 
+func (j *JSONOutputter) TargetFiles() []string {
+	filename, err := cfg.As[string](j.Arg("jsonoutfile"))
+	if err != nil {
+		return nil
+	}
+	return []string{filename}
+}
+
+func (j *JSONOutputter) ReceivesEnvelopes() bool {
+	lineage, _ := cfg.As[bool](j.Arg("json-lineage"))
+	return lineage
+}
+
 func (j *JSONOutputter) Output(val any) error {
 	j.output = append(j.output, val)
 	return nil
 }
 
 func (j *JSONOutputter) Complete() error {
-	return j.encoder.Encode(j.output)
+	if err := j.encoder.Encode(j.output); err != nil {
+		j.file.Close()
+		return err
+	}
+	return j.file.Close()
 }
 
 func (j *JSONOutputter) Params() []cfg.Param {
-	return []cfg.Param{
+	return append([]cfg.Param{
 		cfg.NewParam[string]("jsonoutfile", "the file to write the JSON to").WithDefault("out.json"),
 		cfg.NewParam[int]("indent", "the number of spaces to use for the JSON indentation").WithDefault(0),
-	}
+		cfg.NewParam[bool]("json-lineage", `write each item as {"value": ..., "run_id": ..., "trace_id": ..., "origin": ..., "links": [...]}, if the chain uses envelopes`).WithDefault(false),
+	}, encryptionParams()...)
 }
@@ -0,0 +1,361 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+)
+
+const (
+	DefaultJSONLTypeField = "_type"
+	jsonlValueField       = "_value"
+	jsonlLineageField     = "_lineage"
+)
+
+// JSONLOutputter writes each item as one JSON object per line as soon as it is output, so a
+// long run never holds its results in memory. The file is synced to disk every
+// jsonl-sync-interval seconds, so a crash loses at most the items output since the last sync,
+// and a gzipped file stays readable up to them. With an interval of 0, buffered gzip and
+// encrypted data is flushed to the file after every item instead, and syncing is left to the OS.
+//
+// Each object carries a type discriminator field (see JSONLTypeName) so the file can be read
+// back into typed items with ReadJSONL. Items that don't encode to a JSON object are wrapped
+// as {"_type": ..., "_value": ...}.
+//
+// With jsonl-lineage set and a chain using envelopes, each object also carries the item's
+// chain.Lineage in a "_lineage" field.
+//
+// With encrypt-passphrase or encrypt-recipient set, the file is encrypted after compression;
+// decrypt it with NewDecryptReader before reading it with ReadJSONL.
+type JSONLOutputter struct {
+	*chain.BaseOutputter
+	file         *outputFile
+	gz           *gzip.Writer
+	w            io.Writer
+	typeField    string
+	syncInterval time.Duration
+	lastSync     time.Time
+	lineage      bool
+}
+
+func NewJSONLOutputter(configs ...cfg.Config) chain.Outputter {
+	j := &JSONLOutputter{}
+	j.BaseOutputter = chain.NewBaseOutputter(j, configs...)
+	return j
+}
+
+func (j *JSONLOutputter) Params() []cfg.Param {
+	return append([]cfg.Param{
+		cfg.NewParam[string]("jsonloutfile", "the file to write the JSONL to").WithDefault("out.jsonl"),
+		cfg.NewParam[bool]("jsonl-append", "append to the JSONL file instead of truncating it").WithDefault(false),
+		cfg.NewParam[bool]("jsonl-gzip", "gzip the JSONL file (implied by a .gz extension)").WithDefault(false),
+		cfg.NewParam[int]("jsonl-sync-interval", "seconds between syncs to disk (0 flushes after every item without syncing)").WithDefault(1),
+		cfg.NewParam[string]("jsonl-type-field", "the field holding each item's type (empty to omit)").WithDefault(DefaultJSONLTypeField),
+		cfg.NewParam[bool]("jsonl-lineage", "include each item's lineage, if the chain uses envelopes").WithDefault(false),
+	}, encryptionParams()...)
+}
+
+func (j *JSONLOutputter) Initialize() error {
+	filename, err := cfg.As[string](j.Arg("jsonloutfile"))
+	if err != nil {
+		return fmt.Errorf("error getting jsonloutfile: %w", err)
+	}
+
+	appendFile, _ := cfg.As[bool](j.Arg("jsonl-append"))
+	gzipFile, _ := cfg.As[bool](j.Arg("jsonl-gzip"))
+	syncInterval, _ := cfg.As[int](j.Arg("jsonl-sync-interval"))
+	j.typeField, _ = cfg.As[string](j.Arg("jsonl-type-field"))
+	j.lineage, _ = cfg.As[bool](j.Arg("jsonl-lineage"))
+	j.syncInterval = time.Duration(syncInterval) * time.Second
+
+	encryption, err := encryptionFromArgs(j)
+	if err != nil {
+		return err
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendFile {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	slog.Debug("creating JSONL output file", "filename", filename, "append", appendFile)
+	j.file, err = openOutputFile(filename, flags, encryption)
+	if err != nil {
+		return fmt.Errorf("error creating JSONL: %w", err)
+	}
+
+	j.w = j.file
+	if gzipFile || strings.HasSuffix(filename, ".gz") {
+		j.gz = gzip.NewWriter(j.file)
+		j.w = j.gz
+	}
+
+	j.lastSync = time.Now()
+	return nil
+}
+
+func (j *JSONLOutputter) TargetFiles() []string {
+	filename, err := cfg.As[string](j.Arg("jsonloutfile"))
+	if err != nil {
+		return nil
+	}
+	return []string{filename}
+}
+
+func (j *JSONLOutputter) ReceivesEnvelopes() bool {
+	lineage, _ := cfg.As[bool](j.Arg("jsonl-lineage"))
+	return lineage
+}
+
+func (j *JSONLOutputter) Output(val any) error {
+	var line []byte
+	var err error
+	if envelope, ok := val.(*chain.Envelope); ok {
+		line, err = encodeJSONLineWithLineage(envelope, j.typeField)
+	} else {
+		line, err = encodeJSONLine(val, j.typeField)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := j.w.Write(line); err != nil {
+		return fmt.Errorf("error writing JSONL: %w", err)
+	}
+
+	if j.syncInterval == 0 {
+		return j.flush()
+	}
+	if time.Since(j.lastSync) >= j.syncInterval {
+		return j.sync()
+	}
+	return nil
+}
+
+// flush writes the data buffered by the gzip and encryption writers to the file.
+func (j *JSONLOutputter) flush() error {
+	if j.gz != nil {
+		if err := j.gz.Flush(); err != nil {
+			return fmt.Errorf("error flushing JSONL: %w", err)
+		}
+	}
+	if err := j.file.Flush(); err != nil {
+		return fmt.Errorf("error flushing JSONL: %w", err)
+	}
+	return nil
+}
+
+func (j *JSONLOutputter) sync() error {
+	j.lastSync = time.Now()
+
+	if err := j.flush(); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+func (j *JSONLOutputter) Complete() error {
+	if j.file == nil {
+		return nil
+	}
+
+	if j.gz != nil {
+		if err := j.gz.Close(); err != nil {
+			return fmt.Errorf("error closing JSONL gzip stream: %w", err)
+		}
+	}
+
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("error syncing JSONL: %w", err)
+	}
+	return j.file.Close()
+}
+
+// JSONLTypeName is the type discriminator written for val: its Go type, without pointers,
+// e.g. "types.NPFinding".
+func JSONLTypeName(val any) string {
+	t := reflect.TypeOf(val)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return "nil"
+	}
+	return t.String()
+}
+
+// encodeJSONLine encodes val as a single line of JSON, adding the type field if set.
+func encodeJSONLine(val any, typeField string) ([]byte, error) {
+	raw, err := json.Marshal(val)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding %T as JSON: %w", val, err)
+	}
+
+	if typeField == "" {
+		return append(raw, '\n'), nil
+	}
+
+	typeName, err := json.Marshal(JSONLTypeName(val))
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 1 && trimmed[0] == '{' {
+		fmt.Fprintf(buf, "{%q:%s", typeField, typeName)
+		if body := bytes.TrimSpace(trimmed[1:]); len(body) > 0 && body[0] != '}' {
+			buf.WriteByte(',')
+		}
+		buf.Write(trimmed[1:])
+	} else {
+		fmt.Fprintf(buf, "{%q:%s,%q:%s}", typeField, typeName, jsonlValueField, raw)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// encodeJSONLineWithLineage encodes the envelope's value as encodeJSONLine does, adding its
+// lineage in a "_lineage" field.
+func encodeJSONLineWithLineage(envelope *chain.Envelope, typeField string) ([]byte, error) {
+	line, err := encodeJSONLine(envelope.Value, typeField)
+	if err != nil {
+		return nil, err
+	}
+
+	lineage, err := json.Marshal(envelope.Lineage)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding lineage as JSON: %w", err)
+	}
+
+	line = bytes.TrimSpace(line)
+	if len(line) < 2 || line[0] != '{' {
+		return []byte(fmt.Sprintf("{%q:%s,%q:%s}\n", jsonlValueField, line, jsonlLineageField, lineage)), nil
+	}
+
+	buf := bytes.NewBuffer(line[:len(line)-1])
+	if len(bytes.TrimSpace(line[1:len(line)-1])) > 0 {
+		buf.WriteByte(',')
+	}
+	fmt.Fprintf(buf, "%q:%s}\n", jsonlLineageField, lineage)
+	return buf.Bytes(), nil
+}
+
+// ReadJSONL reads items written by JSONLOutputter. Prototypes are pointers, such as
+// &types.ScannableAsset{} or new(string); each line is decoded into the type a prototype points
+// to when its JSONLTypeName matches the line's type field, and lines of other types are decoded
+// into map[string]any. Gzipped input is detected automatically. A nil or non-pointer prototype
+// is yielded as an error before any item.
+func ReadJSONL(r io.Reader, typeField string, prototypes ...any) iter.Seq2[any, error] {
+	types := make(map[string]reflect.Type)
+	var prototypeErr error
+	for i, prototype := range prototypes {
+		t := reflect.TypeOf(prototype)
+		if t == nil || t.Kind() != reflect.Ptr {
+			prototypeErr = fmt.Errorf("JSONL prototype %d must be a pointer, got %T", i, prototype)
+			break
+		}
+		types[JSONLTypeName(prototype)] = t.Elem()
+	}
+
+	return func(yield func(any, error) bool) {
+		if prototypeErr != nil {
+			yield(nil, prototypeErr)
+			return
+		}
+
+		reader, err := maybeGunzip(r)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+		lineNumber := 0
+		for scanner.Scan() {
+			lineNumber++
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			item, err := decodeJSONLine(line, typeField, types)
+			if err != nil {
+				err = fmt.Errorf("failed to decode JSONL line %d: %w", lineNumber, err)
+			}
+			if !yield(item, err) {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield(nil, fmt.Errorf("failed to read JSONL: %w", err))
+		}
+	}
+}
+
+func decodeJSONLine(line []byte, typeField string, types map[string]reflect.Type) (any, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return nil, err
+	}
+
+	var typeName string
+	if raw, ok := fields[typeField]; ok {
+		if err := json.Unmarshal(raw, &typeName); err != nil {
+			return nil, fmt.Errorf("type field %q is not a string: %w", typeField, err)
+		}
+	}
+
+	body := line
+	wrapperFields := 1
+	if typeName != "" {
+		wrapperFields++
+	}
+	if _, ok := fields[jsonlLineageField]; ok {
+		wrapperFields++
+	}
+	if value, ok := fields[jsonlValueField]; ok && len(fields) == wrapperFields && wrapperFields > 1 {
+		body = value
+	}
+
+	t, ok := types[typeName]
+	if !ok {
+		var item any
+		err := json.Unmarshal(body, &item)
+		return item, err
+	}
+
+	item := reflect.New(t)
+	if err := json.Unmarshal(body, item.Interface()); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", typeName, err)
+	}
+	return item.Elem().Interface(), nil
+}
+
+func maybeGunzip(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return buffered, nil
+	}
+
+	gz, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open gzipped JSONL: %w", err)
+	}
+	return gz, nil
+}
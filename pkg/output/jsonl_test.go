@@ -0,0 +1,192 @@
+package output_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/output"
+	"github.com/praetorian-inc/janus-framework/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLOutputter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.jsonl")
+	outputter := output.NewJSONLOutputter(cfg.WithArg("jsonloutfile", filename))
+	require.NoError(t, outputter.Initialize())
+
+	o := outputter.(*output.JSONLOutputter)
+	require.NoError(t, o.Output(map[string]string{"test": "foobar"}))
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"_type\":\"map[string]string\",\"test\":\"foobar\"}\n", string(content), "items should be written as they arrive")
+
+	require.NoError(t, o.Output("plain"))
+	require.NoError(t, o.Output(&types.ScannableAsset{Target: "example.com", Type: "domain"}))
+	require.NoError(t, o.Complete())
+
+	content, err = os.ReadFile(filename)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(content), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.Equal(t, `{"_type":"string","_value":"plain"}`, string(lines[1]))
+	assert.Contains(t, string(lines[2]), `{"_type":"types.ScannableAsset",`)
+}
+
+func TestJSONLOutputter_Append(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.jsonl")
+	require.NoError(t, os.WriteFile(filename, []byte("{\"existing\":true}\n"), 0644))
+
+	outputter := output.NewJSONLOutputter(
+		cfg.WithArg("jsonloutfile", filename),
+		cfg.WithArg("jsonl-append", true),
+		cfg.WithArg("jsonl-type-field", ""),
+	)
+	require.NoError(t, outputter.Initialize())
+
+	o := outputter.(*output.JSONLOutputter)
+	require.NoError(t, o.Output(map[string]int{"count": 1}))
+	require.NoError(t, o.Complete())
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"existing\":true}\n{\"count\":1}\n", string(content))
+
+	outputter = output.NewJSONLOutputter(cfg.WithArg("jsonloutfile", filename), cfg.WithArg("jsonl-type-field", ""))
+	require.NoError(t, outputter.Initialize())
+	require.NoError(t, outputter.Complete())
+
+	content, err = os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Empty(t, content, "file should be truncated without jsonl-append")
+}
+
+func TestJSONLOutputter_Gzip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.jsonl.gz")
+	outputter := output.NewJSONLOutputter(cfg.WithArg("jsonloutfile", filename), cfg.WithArg("jsonl-sync-interval", 1))
+	require.NoError(t, outputter.Initialize())
+
+	o := outputter.(*output.JSONLOutputter)
+	require.NoError(t, o.Output(map[string]string{"test": "foobar"}))
+	require.NoError(t, o.Complete())
+
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"_type\":\"map[string]string\",\"test\":\"foobar\"}\n", string(content))
+}
+
+func TestJSONLOutputter_GzipFlushesEachItem(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.jsonl.gz")
+	outputter := output.NewJSONLOutputter(cfg.WithArg("jsonloutfile", filename), cfg.WithArg("jsonl-sync-interval", 0))
+	require.NoError(t, outputter.Initialize())
+
+	o := outputter.(*output.JSONLOutputter)
+	require.NoError(t, o.Output("one"))
+	require.NoError(t, o.Output("two"))
+	// the process crashes before the outputter completes
+
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF, "the gzip stream is left unterminated")
+	assert.Equal(t, "{\"_type\":\"string\",\"_value\":\"one\"}\n{\"_type\":\"string\",\"_value\":\"two\"}\n", string(content), "every item output should be readable")
+	require.NoError(t, o.Complete())
+}
+
+func TestReadJSONL(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.jsonl.gz")
+	outputter := output.NewJSONLOutputter(cfg.WithArg("jsonloutfile", filename))
+	require.NoError(t, outputter.Initialize())
+
+	asset := types.ScannableAsset{Target: "example.com", Type: "domain", Domain: "example.com"}
+	o := outputter.(*output.JSONLOutputter)
+	require.NoError(t, o.Output(&asset))
+	require.NoError(t, o.Output("plain"))
+	require.NoError(t, o.Output(map[string]any{"unknown": true}))
+	require.NoError(t, o.Complete())
+
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer file.Close()
+
+	items := []any{}
+	for item, err := range output.ReadJSONL(file, output.DefaultJSONLTypeField, &types.ScannableAsset{}, new(string)) {
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+
+	require.Len(t, items, 3)
+	assert.Equal(t, asset, items[0])
+	assert.Equal(t, "plain", items[1])
+	assert.Equal(t, map[string]any{"_type": "map[string]interface {}", "unknown": true}, items[2])
+}
+
+func TestReadJSONL_InvalidPrototypes(t *testing.T) {
+	for _, prototype := range []any{nil, types.ScannableAsset{}, ""} {
+		t.Run(fmt.Sprintf("%T", prototype), func(t *testing.T) {
+			errs := []error{}
+			for item, err := range output.ReadJSONL(strings.NewReader(`{"_type":"string","_value":"plain"}`), output.DefaultJSONLTypeField, prototype) {
+				assert.Nil(t, item)
+				errs = append(errs, err)
+			}
+
+			require.Len(t, errs, 1)
+			assert.ErrorContains(t, errs[0], "must be a pointer")
+		})
+	}
+}
+
+func TestJSONLOutputter_Lineage(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.jsonl")
+	outputter := output.NewJSONLOutputter(
+		cfg.WithArg("jsonloutfile", filename),
+		cfg.WithArg("jsonl-lineage", true),
+	)
+	require.NoError(t, outputter.Initialize())
+	assert.True(t, outputter.(chain.EnvelopeReceiver).ReceivesEnvelopes())
+
+	lineage := chain.Lineage{RunID: "run", TraceID: "trace", Origin: "example.com", Links: []string{"Resolve"}}
+	asset := types.ScannableAsset{Target: "example.com", Type: "domain"}
+
+	o := outputter.(*output.JSONLOutputter)
+	require.NoError(t, o.Output(&chain.Envelope{Value: &asset, Lineage: lineage}))
+	require.NoError(t, o.Output(&chain.Envelope{Value: "plain", Lineage: lineage}))
+	require.NoError(t, o.Complete())
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(content), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), `"_lineage":{"run_id":"run","trace_id":"trace","origin":"example.com","links":["Resolve"]}}`)
+	assert.Equal(t, `{"_type":"string","_value":"plain","_lineage":{"run_id":"run","trace_id":"trace","origin":"example.com","links":["Resolve"]}}`, string(lines[1]))
+
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer file.Close()
+
+	items := []any{}
+	for item, err := range output.ReadJSONL(file, output.DefaultJSONLTypeField, &types.ScannableAsset{}) {
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+	assert.Equal(t, []any{asset, "plain"}, items)
+}
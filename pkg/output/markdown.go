@@ -1,8 +1,15 @@
 package output
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"reflect"
+	"slices"
 	"sort"
 	"strings"
 
@@ -16,12 +23,18 @@ type Markdownable interface {
 	Values() []any
 }
 
+// MarkdownTabler is implemented by Markdownables that choose the table they are written to.
+// Others are written to a table named after their type.
+type MarkdownTabler interface {
+	TableName() string
+}
+
 type Sorter func(a, b string) bool
 
 type mdColumn struct {
 	name   string
-	values []string
 	width  int
+	length int // number of rows, including rows beyond the row limit that aren't kept
 }
 
 type mdCell struct {
@@ -30,34 +43,60 @@ type mdCell struct {
 	value  any
 }
 
+type mdTable struct {
+	name                string
+	columns             map[string]mdColumn
+	longestColumnLength int
+	rows                []map[string]string // cells of the rows after the spilled ones, by column
+	spilled             int
+	late                map[int]map[string]string // cells added to spilled rows, by row and column
+	spill               *mdSpill
+}
+
+// MarkdownOutputter writes Markdownable items as Markdown tables, one table per item type or
+// per MarkdownTabler table name. A single table is written on its own; several tables each
+// get a heading. Setting md-title adds a title and a summary of the tables' row counts.
+//
+// Pipes and newlines in cells are escaped. With md-buffer-rows set, only the last that many rows
+// of each table are held in memory; older rows are spilled to a temporary file, encrypted if the
+// output is, and streamed back when the tables are written. Cells added to spilled rows are held
+// in memory and merged into them as they are written. With md-max-rows set, only that many rows
+// of each table are kept and written, followed by a count of the rows left out. With
+// encrypt-passphrase or encrypt-recipient set, the file is encrypted (see NewDecryptReader).
 type MarkdownOutputter struct {
 	*chain.BaseOutputter
-	table               map[string]mdColumn
-	outfile             string
-	sorter              Sorter
-	longestColumnLength int
+	tables     map[string]*mdTable
+	order      []string
+	columns    []string
+	outfile    string
+	sorter     Sorter
+	title      string
+	maxRows    int
+	bufferRows int
+	encryption *EncryptionOptions
 }
 
 func NewMarkdownOutputter(configs ...cfg.Config) chain.Outputter {
-	m := &MarkdownOutputter{table: make(map[string]mdColumn)}
+	m := &MarkdownOutputter{tables: make(map[string]*mdTable)}
 	m.BaseOutputter = chain.NewBaseOutputter(m, configs...)
 	return m
 }
 
 func (m *MarkdownOutputter) Params() []cfg.Param {
-	return []cfg.Param{
+	return append([]cfg.Param{
 		cfg.NewParam[string]("mdoutfile", "the file to write the markdown to").WithDefault("out.md"),
 		cfg.NewParam[[]string]("columns", "the columns to write to the markdown"),
 		cfg.NewParam[Sorter]("sorter", "sorter function to sort the columns (defaults to alphabetical)").WithDefault(func(a, b string) bool { return a < b }),
-	}
+		cfg.NewParam[string]("md-title", "title of the markdown document; also adds a summary of the tables"),
+		cfg.NewParam[int]("md-max-rows", "maximum rows to write per table (0 for no limit)").WithDefault(0),
+		cfg.NewParam[int]("md-buffer-rows", "rows per table to hold in memory before spilling to a temporary file (0 holds every row)").WithDefault(0),
+	}, encryptionParams()...)
 }
 
 func (m *MarkdownOutputter) Initialize() error {
 	columns, err := cfg.As[[]string](m.Arg("columns"))
 	if err == nil {
-		for _, column := range columns {
-			m.table[column] = mdColumn{name: column, values: []string{}}
-		}
+		m.columns = columns
 	}
 
 	outfile, err := cfg.As[string](m.Arg("mdoutfile"))
@@ -74,9 +113,30 @@ func (m *MarkdownOutputter) Initialize() error {
 	}
 	m.sorter = sorter
 
+	m.title, _ = cfg.As[string](m.Arg("md-title"))
+	m.maxRows, _ = cfg.As[int](m.Arg("md-max-rows"))
+	m.bufferRows, _ = cfg.As[int](m.Arg("md-buffer-rows"))
+
+	m.encryption, err = encryptionFromArgs(m)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+func (m *MarkdownOutputter) AcceptedTypes() []reflect.Type {
+	return []reflect.Type{reflect.TypeFor[Markdownable]()}
+}
+
+func (m *MarkdownOutputter) TargetFiles() []string {
+	outfile, err := cfg.As[string](m.Arg("mdoutfile"))
+	if err != nil {
+		return nil
+	}
+	return []string{outfile}
+}
+
 func (m *MarkdownOutputter) Output(mdData Markdownable) error {
 	columns := mdData.Columns()
 	rows := mdData.Rows()
@@ -97,17 +157,36 @@ func (m *MarkdownOutputter) Output(mdData Markdownable) error {
 		cells = append(cells, cell)
 	}
 
+	table := m.tableFor(mdData)
 	for _, cell := range cells {
-		err := m.processColumn(cell.column, cell.row, cell.value)
+		err := m.processColumn(table, cell.column, cell.row, cell.value)
 		if err != nil {
 			return fmt.Errorf("error processing column: %w", err)
 		}
 	}
 
-	return nil
+	return m.spillRows(table)
 }
 
-func (m *MarkdownOutputter) processColumn(columnName string, rowID int, cellItem any) error {
+func (m *MarkdownOutputter) tableFor(mdData Markdownable) *mdTable {
+	name := JSONLTypeName(mdData)
+	if tabler, ok := mdData.(MarkdownTabler); ok {
+		name = tabler.TableName()
+	}
+
+	table, ok := m.tables[name]
+	if !ok {
+		table = &mdTable{name: name, columns: make(map[string]mdColumn)}
+		for _, column := range m.columns {
+			table.columns[column] = mdColumn{name: column}
+		}
+		m.tables[name] = table
+		m.order = append(m.order, name)
+	}
+	return table
+}
+
+func (m *MarkdownOutputter) processColumn(table *mdTable, columnName string, rowID int, cellItem any) error {
 	if columnName == "" && cellItem != nil {
 		columnName = fmt.Sprintf("%T", cellItem)
 	} else if columnName == "" && cellItem == nil {
@@ -123,29 +202,139 @@ func (m *MarkdownOutputter) processColumn(columnName string, rowID int, cellItem
 	} else {
 		cellData = fmt.Sprintf("%v", cellItem)
 	}
+	cellData = escapeMarkdownCell(cellData)
 
-	column := m.table[columnName]
+	column := table.columns[columnName]
 	column.name = columnName
-	column.width = max(column.width, len(cellData), len(columnName))
+	column.width = max(column.width, len(escapeMarkdownCell(columnName)))
 
-	for len(column.values) < rowID {
-		column.values = append(column.values, "")
-	}
 	if rowID == 0 {
-		column.values = append(column.values, cellData)
-	} else {
-		column.values[rowID-1] = cellData
+		rowID = column.length + 1
+	}
+	column.length = max(column.length, rowID)
+
+	if m.maxRows <= 0 || rowID <= m.maxRows {
+		column.width = max(column.width, len(cellData))
+		m.rowFor(table, rowID)[columnName] = cellData
 	}
-	m.longestColumnLength = max(m.longestColumnLength, len(column.values))
+	table.longestColumnLength = max(table.longestColumnLength, column.length)
 
-	m.table[columnName] = column
+	table.columns[columnName] = column
 
 	return nil
 }
 
+// rowFor returns the cells of a row of the table, by column. Cells of spilled rows are held
+// apart and merged into them when the table is written.
+func (m *MarkdownOutputter) rowFor(table *mdTable, rowID int) map[string]string {
+	if rowID <= table.spilled {
+		if table.late == nil {
+			table.late = map[int]map[string]string{}
+		}
+		if table.late[rowID] == nil {
+			table.late[rowID] = map[string]string{}
+		}
+		return table.late[rowID]
+	}
+
+	for len(table.rows) < rowID-table.spilled {
+		table.rows = append(table.rows, nil)
+	}
+	row := table.rows[rowID-table.spilled-1]
+	if row == nil {
+		row = map[string]string{}
+		table.rows[rowID-table.spilled-1] = row
+	}
+	return row
+}
+
+// escapeMarkdownCell keeps a value on one line of its cell: pipes are escaped and line breaks
+// become <br>.
+func escapeMarkdownCell(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	value = strings.ReplaceAll(value, "\r", "\n")
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+// spillRows moves the table's oldest rows to its spill file once it holds twice md-buffer-rows
+// rows, keeping the newest md-buffer-rows in memory.
+func (m *MarkdownOutputter) spillRows(table *mdTable) error {
+	if m.bufferRows <= 0 || len(table.rows) < 2*m.bufferRows {
+		return nil
+	}
+
+	if table.spill == nil {
+		spill, err := newMDSpill(m.encryption != nil)
+		if err != nil {
+			return fmt.Errorf("error creating markdown spill file: %w", err)
+		}
+		table.spill = spill
+	}
+
+	excess := len(table.rows) - m.bufferRows
+	for _, row := range table.rows[:excess] {
+		if err := table.spill.write(row); err != nil {
+			return fmt.Errorf("error spilling markdown rows: %w", err)
+		}
+	}
+	if err := table.spill.w.Flush(); err != nil {
+		return fmt.Errorf("error spilling markdown rows: %w", err)
+	}
+	table.rows = slices.Clone(table.rows[excess:])
+	table.spilled += excess
+	return nil
+}
+
 func (m *MarkdownOutputter) Complete() error {
+	defer m.removeSpills()
+
+	file, err := createOutputFile(m.outfile, m.encryption)
+	if err != nil {
+		return fmt.Errorf("error creating markdown file: %w", err)
+	}
+
+	w := bufio.NewWriter(file)
+	if m.title != "" {
+		fmt.Fprintf(w, "# %s\n\n", m.title)
+		for _, name := range m.order {
+			fmt.Fprintf(w, "- %s: %d rows\n", name, m.tables[name].longestColumnLength)
+		}
+		w.WriteString("\n")
+	}
+
+	for i, name := range m.order {
+		if i > 0 {
+			w.WriteString("\n")
+		}
+		if len(m.order) > 1 || m.title != "" {
+			fmt.Fprintf(w, "## %s\n\n", name)
+		}
+		if err := m.writeTable(w, m.tables[name]); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (m *MarkdownOutputter) removeSpills() {
+	for _, table := range m.tables {
+		if table.spill != nil {
+			table.spill.remove()
+			table.spill = nil
+		}
+	}
+}
+
+func (m *MarkdownOutputter) writeTable(w io.Writer, table *mdTable) error {
 	columns := []mdColumn{}
-	for _, column := range m.table {
+	for _, column := range table.columns {
 		columns = append(columns, column)
 	}
 
@@ -153,27 +342,153 @@ func (m *MarkdownOutputter) Complete() error {
 		return m.sorter(columns[i].name, columns[j].name)
 	})
 
-	rows := make([]string, m.longestColumnLength+2) // +1 for header, +1 for separator
+	shown := table.longestColumnLength
+	if m.maxRows > 0 {
+		shown = min(shown, m.maxRows)
+	}
+
+	header := &strings.Builder{}
+	separator := &strings.Builder{}
 	for _, column := range columns {
-		rows[0] += fmt.Sprintf("| %-*s ", column.width, column.name)
-		rows[1] += fmt.Sprintf("| %-s ", strings.Repeat("-", column.width))
-		for len(column.values) < m.longestColumnLength {
-			column.values = append(column.values, "")
+		fmt.Fprintf(header, "| %-*s ", column.width, escapeMarkdownCell(column.name))
+		fmt.Fprintf(separator, "| %-s ", strings.Repeat("-", column.width))
+	}
+	if _, err := fmt.Fprintf(w, "%s|\n%s|\n", header, separator); err != nil {
+		return err
+	}
+
+	written := 0
+	writeRow := func(row map[string]string) error {
+		if written >= shown {
+			return nil
+		}
+		written++
+
+		line := &strings.Builder{}
+		for _, column := range columns {
+			fmt.Fprintf(line, "| %-*s ", column.width, row[column.name])
+		}
+		line.WriteString("|\n")
+		_, err := io.WriteString(w, line.String())
+		return err
+	}
+
+	if table.spill != nil {
+		spilled := 0
+		err := table.spill.rows(func(row map[string]string) error {
+			spilled++
+			maps.Copy(row, table.late[spilled])
+			return writeRow(row)
+		})
+		if err != nil {
+			return fmt.Errorf("error reading markdown spill file: %w", err)
+		}
+	}
+	for _, row := range table.rows {
+		if err := writeRow(row); err != nil {
+			return err
+		}
+	}
+	for written < shown {
+		if err := writeRow(nil); err != nil {
+			return err
+		}
+	}
+
+	if hidden := table.longestColumnLength - shown; hidden > 0 {
+		if _, err := fmt.Fprintf(w, "\n_%d more rows not shown_\n", hidden); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mdSpill holds the rows of a table that no longer fit in memory, as JSON lines in a temporary
+// file. The rows of encrypted outputs are encrypted to a throwaway key.
+type mdSpill struct {
+	file     *os.File
+	enc      *EncryptWriter
+	w        *bufio.Writer
+	identity string
+}
+
+func newMDSpill(encrypted bool) (*mdSpill, error) {
+	file, err := os.CreateTemp("", "janus-md-*.jsonl")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &mdSpill{file: file}
+	var w io.Writer = file
+	if encrypted {
+		identity, recipient, err := GenerateRecipientKey()
+		if err != nil {
+			s.remove()
+			return nil, err
 		}
-		for i, value := range column.values {
-			rows[i+2] += fmt.Sprintf("| %-*s ", column.width, value)
+
+		s.enc, err = NewEncryptWriter(file, EncryptionOptions{Recipient: recipient})
+		if err != nil {
+			s.remove()
+			return nil, err
 		}
+		s.identity = identity
+		w = s.enc
 	}
 
-	table := strings.Join(rows, "|\n") + "|\n"
+	s.w = bufio.NewWriter(w)
+	return s, nil
+}
 
-	writer, err := os.Create(m.outfile)
+func (s *mdSpill) write(row map[string]string) error {
+	line, err := json.Marshal(row)
 	if err != nil {
-		return fmt.Errorf("error creating markdown file: %w", err)
+		return err
 	}
-	defer writer.Close()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
 
-	writer.WriteString(table)
+// rows calls fn with each spilled row, in order. No rows can be written afterwards.
+func (s *mdSpill) rows(fn func(map[string]string) error) error {
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	if s.enc != nil {
+		if err := s.enc.Close(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
 
-	return nil
+	var r io.Reader = s.file
+	if s.enc != nil {
+		var err error
+		r, err = NewDecryptReader(s.file, DecryptionOptions{Identity: s.identity})
+		if err != nil {
+			return err
+		}
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for {
+		row := map[string]string{}
+		err := decoder.Decode(&row)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *mdSpill) remove() {
+	s.file.Close()
+	os.Remove(s.file.Name())
 }
@@ -3,6 +3,8 @@ package output_test
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
@@ -221,3 +223,121 @@ func TestMarkdownOutputter_MultipleFieldsWithMissings(t *testing.T) {
 
 	os.Remove("test.md")
 }
+
+type mockTabledMarkdownable struct {
+	mockMarkdownable
+	table string
+}
+
+func (m *mockTabledMarkdownable) TableName() string {
+	return m.table
+}
+
+func TestMarkdownOutputter_MultipleTables(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.md")
+	outputter := output.NewMarkdownOutputter(cfg.WithArg("mdoutfile", filename), cfg.WithArg("md-title", "Findings"))
+	require.NoError(t, outputter.Initialize())
+
+	markdownOutputter := outputter.(*output.MarkdownOutputter)
+	require.NoError(t, markdownOutputter.Output(&mockTabledMarkdownable{mockMarkdownable{column: "host", value: "a.com"}, "Hosts"}))
+	require.NoError(t, markdownOutputter.Output(&mockTabledMarkdownable{mockMarkdownable{column: "secret", value: "x|y\nz"}, "Secrets"}))
+	require.NoError(t, markdownOutputter.Output(&mockTabledMarkdownable{mockMarkdownable{column: "host", value: "b.com"}, "Hosts"}))
+	require.NoError(t, outputter.Complete())
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	expected := `# Findings
+
+- Hosts: 2 rows
+- Secrets: 1 rows
+
+## Hosts
+
+| host  |
+| ----- |
+| a.com |
+| b.com |
+
+## Secrets
+
+| secret    |
+| --------- |
+| x\|y<br>z |
+`
+	assert.Equal(t, expected, string(content))
+}
+
+func TestMarkdownOutputter_MaxRows(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.md")
+	outputter := output.NewMarkdownOutputter(cfg.WithArg("mdoutfile", filename), cfg.WithArg("md-max-rows", 2))
+	require.NoError(t, outputter.Initialize())
+
+	markdownOutputter := outputter.(*output.MarkdownOutputter)
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, markdownOutputter.Output(&mockMarkdownable{column: "columnA", value: fmt.Sprintf("value%d", i)}))
+	}
+	require.NoError(t, markdownOutputter.Output(&mockMarkdownable{column: "columnA", value: "a much longer hidden value"}))
+	require.NoError(t, outputter.Complete())
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	expected := `| columnA |
+| ------- |
+| value1  |
+| value2  |
+
+_4 more rows not shown_
+`
+	assert.Equal(t, expected, string(content))
+}
+
+func TestMarkdownOutputter_SpillsRows(t *testing.T) {
+	outDir := t.TempDir()
+	spillDir := t.TempDir()
+	t.Setenv("TMPDIR", spillDir)
+
+	for _, encrypted := range []bool{false, true} {
+		t.Run(fmt.Sprintf("encrypted=%t", encrypted), func(t *testing.T) {
+			filename := filepath.Join(outDir, fmt.Sprintf("test-%t.md", encrypted))
+			configs := []cfg.Config{cfg.WithArg("mdoutfile", filename), cfg.WithArg("md-buffer-rows", 2)}
+			if encrypted {
+				configs = append(configs, cfg.WithArg("encrypt-passphrase", "hunter2"))
+			}
+			outputter := output.NewMarkdownOutputter(configs...)
+			require.NoError(t, outputter.Initialize())
+
+			markdownOutputter := outputter.(*output.MarkdownOutputter)
+			expected := "| columnA |\n| ------- |\n"
+			for i := 1; i <= 9; i++ {
+				require.NoError(t, markdownOutputter.Output(&mockMarkdownable{column: "columnA", value: fmt.Sprintf("value%d", i)}))
+				expected += fmt.Sprintf("| value%d  |\n", i)
+			}
+
+			spilled, err := os.ReadDir(spillDir)
+			require.NoError(t, err)
+			require.Len(t, spilled, 1, "older rows should be spilled to a temporary file")
+			raw, err := os.ReadFile(filepath.Join(spillDir, spilled[0].Name()))
+			require.NoError(t, err)
+			assert.Equal(t, !encrypted, strings.Contains(string(raw), "value1"))
+
+			require.NoError(t, markdownOutputter.Output(&mockMarkdownable{column: "columnA", row: 1, value: "updated"}), "spilled rows should still be writable")
+			expected = strings.Replace(expected, "| value1  |", "| updated |", 1)
+			require.NoError(t, outputter.Complete())
+
+			var content []byte
+			if encrypted {
+				content, err = output.DecryptFile(filename, output.DecryptionOptions{Passphrase: "hunter2"})
+			} else {
+				content, err = os.ReadFile(filename)
+			}
+			require.NoError(t, err)
+			assert.Equal(t, expected, string(content))
+
+			spilled, err = os.ReadDir(spillDir)
+			require.NoError(t, err)
+			assert.Empty(t, spilled, "spill files should be removed")
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/types"
+)
+
+const (
+	SARIFVersion = "2.1.0"
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIFable is implemented by findings that can be reported as SARIF results. The result's
+// RuleID must match the ID of the returned rule.
+type SARIFable interface {
+	SARIFRule() SARIFRule
+	SARIFResult() SARIFResult
+}
+
+type SARIFLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+type SARIFRule struct {
+	ID               string         `json:"id"`
+	Name             string         `json:"name,omitempty"`
+	ShortDescription *SARIFMessage  `json:"shortDescription,omitempty"`
+	FullDescription  *SARIFMessage  `json:"fullDescription,omitempty"`
+	Properties       map[string]any `json:"properties,omitempty"`
+}
+
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+type SARIFResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level,omitempty"`
+	Message             SARIFMessage      `json:"message"`
+	Locations           []SARIFLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+type SARIFLocation struct {
+	PhysicalLocation *SARIFPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+	ContextRegion    *SARIFRegion          `json:"contextRegion,omitempty"`
+}
+
+type SARIFArtifactLocation struct {
+	URI         string        `json:"uri,omitempty"`
+	Description *SARIFMessage `json:"description,omitempty"`
+}
+
+type SARIFRegion struct {
+	StartLine int           `json:"startLine,omitempty"`
+	Snippet   *SARIFMessage `json:"snippet,omitempty"`
+}
+
+type SARIFLogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// SARIFOutputter writes SARIFable items, and NoseyParker findings, as a SARIF 2.1.0 log with
+// a single run. Each distinct rule is listed once in tool.driver.rules.
+type SARIFOutputter struct {
+	*chain.BaseOutputter
+	outfile   string
+	rules     []SARIFRule
+	ruleIndex map[string]int
+	results   []SARIFResult
+}
+
+func NewSARIFOutputter(configs ...cfg.Config) chain.Outputter {
+	s := &SARIFOutputter{ruleIndex: make(map[string]int)}
+	s.BaseOutputter = chain.NewBaseOutputter(s, configs...)
+	return s
+}
+
+func (s *SARIFOutputter) Params() []cfg.Param {
+	return []cfg.Param{
+		cfg.NewParam[string]("sarifoutfile", "the file to write the SARIF log to").WithDefault("out.sarif"),
+		cfg.NewParam[string]("sarif-tool-name", "the tool name reported in the SARIF log").WithDefault("janus"),
+	}
+}
+
+func (s *SARIFOutputter) Initialize() error {
+	outfile, err := cfg.As[string](s.Arg("sarifoutfile"))
+	if err != nil {
+		return fmt.Errorf("error getting sarifoutfile: %w", err)
+	}
+	s.outfile = outfile
+	return nil
+}
+
+func (s *SARIFOutputter) AcceptedTypes() []reflect.Type {
+	return []reflect.Type{reflect.TypeFor[SARIFable](), reflect.TypeFor[types.NPFinding]()}
+}
+
+func (s *SARIFOutputter) TargetFiles() []string {
+	outfile, err := cfg.As[string](s.Arg("sarifoutfile"))
+	if err != nil {
+		return nil
+	}
+	return []string{outfile}
+}
+
+func (s *SARIFOutputter) Output(val any) error {
+	sarifable, ok := asSARIFable(val)
+	if !ok {
+		return fmt.Errorf("%T cannot be converted to SARIF", val)
+	}
+
+	rule := sarifable.SARIFRule()
+	index, ok := s.ruleIndex[rule.ID]
+	if !ok {
+		index = len(s.rules)
+		s.ruleIndex[rule.ID] = index
+		s.rules = append(s.rules, rule)
+	}
+
+	result := sarifable.SARIFResult()
+	result.RuleID = rule.ID
+	result.RuleIndex = index
+	s.results = append(s.results, result)
+	return nil
+}
+
+func (s *SARIFOutputter) Complete() error {
+	toolName, err := cfg.As[string](s.Arg("sarif-tool-name"))
+	if err != nil || toolName == "" {
+		toolName = "janus"
+	}
+
+	log := SARIFLog{
+		Version: SARIFVersion,
+		Schema:  SARIFSchema,
+		Runs: []SARIFRun{{
+			Tool:    SARIFTool{Driver: SARIFDriver{Name: toolName, Rules: s.rules}},
+			Results: s.results,
+		}},
+	}
+	if log.Runs[0].Tool.Driver.Rules == nil {
+		log.Runs[0].Tool.Driver.Rules = []SARIFRule{}
+	}
+	if log.Runs[0].Results == nil {
+		log.Runs[0].Results = []SARIFResult{}
+	}
+
+	slog.Debug("creating SARIF output file", "filename", s.outfile)
+	writer, err := os.Create(s.outfile)
+	if err != nil {
+		return fmt.Errorf("error creating SARIF: %w", err)
+	}
+	defer writer.Close()
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+func asSARIFable(val any) (SARIFable, bool) {
+	switch v := val.(type) {
+	case SARIFable:
+		return v, true
+	case types.NPFinding:
+		return npFindingSARIF{&v}, true
+	case *types.NPFinding:
+		return npFindingSARIF{v}, true
+	default:
+		return nil, false
+	}
+}
+
+// npFindingSARIF adapts a NoseyParker finding to SARIF. Git provenance becomes the blob path
+// in the repository, Docker provenance the file within the image layer, and cloud provenance
+// a logical location naming the resource.
+type npFindingSARIF struct {
+	*types.NPFinding
+}
+
+func (f npFindingSARIF) SARIFRule() SARIFRule {
+	id := f.RuleTextID
+	if id == "" {
+		id = f.RuleName
+	}
+
+	return SARIFRule{
+		ID:               id,
+		Name:             f.RuleName,
+		ShortDescription: &SARIFMessage{Text: f.RuleName},
+		Properties:       map[string]any{"tags": []string{"security", "secret"}},
+	}
+}
+
+func (f npFindingSARIF) SARIFResult() SARIFResult {
+	result := SARIFResult{
+		Level:     "error",
+		Message:   SARIFMessage{Text: fmt.Sprintf("Secret detected: %s", f.RuleName)},
+		Locations: []SARIFLocation{f.location()},
+	}
+
+	if f.FindingID != "" {
+		result.PartialFingerprints = map[string]string{"noseyparkerFindingId/v1": f.FindingID}
+	}
+	if f.Location.SourceSpan.Start.Line <= 0 && f.Snippet.Matching != "" {
+		result.Properties = map[string]any{"snippet": f.Snippet.Matching}
+	}
+	return result
+}
+
+func (f npFindingSARIF) location() SARIFLocation {
+	p := f.Provenance
+
+	blobPath := ""
+	commitID := ""
+	if p.FirstCommit != nil {
+		blobPath = p.FirstCommit.BlobPath
+		commitID = p.FirstCommit.CommitMetadata.CommitID
+	}
+
+	location := SARIFLocation{}
+	uri := ""
+
+	switch {
+	case p.Platform == "docker":
+		uri = blobPath
+		if uri == "" {
+			uri = p.RepoPath
+		}
+		if p.ResourceType == "layer" {
+			location.LogicalLocations = append(location.LogicalLocations, SARIFLogicalLocation{Name: p.RepoPath, Kind: "layer"})
+		}
+		location.LogicalLocations = append(location.LogicalLocations, SARIFLogicalLocation{FullyQualifiedName: p.ResourceID, Kind: "image"})
+	case p.ResourceID != "":
+		uri = p.RepoPath
+		location.LogicalLocations = append(location.LogicalLocations, SARIFLogicalLocation{
+			Name:               p.ResourceID,
+			FullyQualifiedName: strings.Trim(strings.Join([]string{p.Platform, p.AccountID, p.Region, p.ResourceType, p.ResourceID}, "/"), "/"),
+			Kind:               p.ResourceType,
+		})
+	default:
+		uri = blobPath
+		if uri == "" {
+			uri = p.RepoPath
+		}
+	}
+
+	physical := &SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: uri}}
+	if p.RepoPath != "" && p.Platform != "docker" && blobPath != "" {
+		description := fmt.Sprintf("%s in %s", blobPath, p.RepoPath)
+		if commitID != "" {
+			description += fmt.Sprintf(" at %s", commitID)
+		}
+		physical.ArtifactLocation.Description = &SARIFMessage{Text: description}
+	}
+
+	// A region must have a start, so without the match's line the snippet is left to the
+	// result's properties.
+	if line := f.Location.SourceSpan.Start.Line; line > 0 && f.Snippet.Matching != "" {
+		physical.Region = &SARIFRegion{StartLine: line, Snippet: &SARIFMessage{Text: f.Snippet.Matching}}
+		physical.ContextRegion = &SARIFRegion{
+			StartLine: max(1, line-strings.Count(f.Snippet.Before, "\n")),
+			Snippet:   &SARIFMessage{Text: f.Snippet.Before + f.Snippet.Matching + f.Snippet.After},
+		}
+	}
+
+	if uri != "" || physical.Region != nil {
+		location.PhysicalLocation = physical
+	}
+	return location
+}
@@ -0,0 +1,111 @@
+package output_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/output"
+	"github.com/praetorian-inc/janus-framework/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sarifTestFinding struct {
+	Check string
+}
+
+func (f sarifTestFinding) SARIFRule() output.SARIFRule {
+	return output.SARIFRule{ID: f.Check}
+}
+
+func (f sarifTestFinding) SARIFResult() output.SARIFResult {
+	return output.SARIFResult{Level: "warning", Message: output.SARIFMessage{Text: "failed " + f.Check}}
+}
+
+func TestSARIFOutputter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.sarif")
+	outputter := output.NewSARIFOutputter(cfg.WithArg("sarifoutfile", filename))
+	require.NoError(t, outputter.Initialize())
+
+	gitFinding := types.NPFinding{
+		FindingID:  "abc123",
+		RuleName:   "AWS API Key",
+		RuleTextID: "np.aws.1",
+		Provenance: types.NPProvenance{
+			Kind:        "git_repo",
+			RepoPath:    "https://github.com/org/repo",
+			FirstCommit: &types.NPCommitMetadata{BlobPath: "config/prod.env"},
+		},
+		Snippet:  types.NPSnippet{Before: "# prod\nKEY=", Matching: "AKIAEXAMPLE", After: "\n"},
+		Location: types.NPLocation{SourceSpan: types.NPSourceSpan{Start: types.NPPosition{Line: 12, Column: 5}}},
+	}
+	dockerFinding := types.NPFinding{
+		RuleName:   "AWS API Key",
+		RuleTextID: "np.aws.1",
+		Provenance: types.NPProvenance{
+			Platform:     "docker",
+			ResourceType: "layer",
+			ResourceID:   "nginx:latest",
+			RepoPath:     "sha256:layer",
+			FirstCommit:  &types.NPCommitMetadata{BlobPath: "etc/secret"},
+		},
+		Snippet: types.NPSnippet{Matching: "AKIAOTHER"},
+	}
+
+	o := outputter.(*output.SARIFOutputter)
+	require.NoError(t, o.Output(gitFinding))
+	require.NoError(t, o.Output(&dockerFinding))
+	require.NoError(t, o.Output(sarifTestFinding{Check: "custom-check"}))
+	assert.Error(t, o.Output("not a finding"))
+	require.NoError(t, o.Complete())
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	var log output.SARIFLog
+	require.NoError(t, json.Unmarshal(content, &log))
+
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "janus", run.Tool.Driver.Name)
+
+	require.Len(t, run.Tool.Driver.Rules, 2, "rules should be deduplicated")
+	assert.Equal(t, "np.aws.1", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "AWS API Key", run.Tool.Driver.Rules[0].Name)
+	assert.Equal(t, "custom-check", run.Tool.Driver.Rules[1].ID)
+
+	require.Len(t, run.Results, 3)
+
+	git := run.Results[0]
+	assert.Equal(t, "np.aws.1", git.RuleID)
+	assert.Equal(t, 0, git.RuleIndex)
+	assert.Equal(t, "abc123", git.PartialFingerprints["noseyparkerFindingId/v1"])
+	physical := git.Locations[0].PhysicalLocation
+	assert.Equal(t, "config/prod.env", physical.ArtifactLocation.URI)
+	assert.Equal(t, 12, physical.Region.StartLine)
+	assert.Equal(t, "AKIAEXAMPLE", physical.Region.Snippet.Text)
+	assert.Equal(t, 11, physical.ContextRegion.StartLine)
+	assert.Equal(t, "# prod\nKEY=AKIAEXAMPLE\n", physical.ContextRegion.Snippet.Text)
+	assert.Empty(t, git.Properties)
+
+	docker := run.Results[1]
+	assert.Equal(t, 0, docker.RuleIndex)
+	assert.Equal(t, "etc/secret", docker.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Nil(t, docker.Locations[0].PhysicalLocation.Region, "regions need a start line")
+	assert.Nil(t, docker.Locations[0].PhysicalLocation.ContextRegion)
+	assert.Equal(t, "AKIAOTHER", docker.Properties["snippet"])
+	assert.NotContains(t, string(content), `"region":{"snippet"`)
+	assert.Equal(t, []output.SARIFLogicalLocation{
+		{Name: "sha256:layer", Kind: "layer"},
+		{FullyQualifiedName: "nginx:latest", Kind: "image"},
+	}, docker.Locations[0].LogicalLocations)
+
+	custom := run.Results[2]
+	assert.Equal(t, "custom-check", custom.RuleID)
+	assert.Equal(t, 1, custom.RuleIndex)
+	assert.Equal(t, "warning", custom.Level)
+}
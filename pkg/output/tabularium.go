@@ -0,0 +1,142 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/tabularium/pkg/model/model"
+)
+
+// Tabularizable is implemented by results that map onto tabularium model objects, so they can
+// be ingested into Chariot without translating them by hand. A result may map onto several
+// models, e.g. an asset and its ports. ScannableAsset, NPFinding and DockerImage implement it.
+type Tabularizable interface {
+	ToTabularium() ([]model.GraphModel, error)
+}
+
+// TabulariumOutputter writes the tabularium models of Tabularizable items. By default each
+// model is written as one JSON object per line. With tabularium-batch-size set, models are
+// instead grouped into JSON arrays of up to that many models, one array per line, for
+// ingestion endpoints that take batched payloads.
+type TabulariumOutputter struct {
+	*chain.BaseOutputter
+	file      *os.File
+	w         *bufio.Writer
+	batchSize int
+	batch     []model.GraphModel
+}
+
+func NewTabulariumOutputter(configs ...cfg.Config) chain.Outputter {
+	t := &TabulariumOutputter{}
+	t.BaseOutputter = chain.NewBaseOutputter(t, configs...)
+	return t
+}
+
+func (t *TabulariumOutputter) Params() []cfg.Param {
+	return []cfg.Param{
+		cfg.NewParam[string]("tabulariumoutfile", "the file to write the tabularium models to").WithDefault("out.tabularium.jsonl"),
+		cfg.NewParam[int]("tabularium-batch-size", "models per batched payload (0 writes one model per line)").WithDefault(0),
+	}
+}
+
+func (t *TabulariumOutputter) Initialize() error {
+	filename, err := cfg.As[string](t.Arg("tabulariumoutfile"))
+	if err != nil {
+		return fmt.Errorf("error getting tabulariumoutfile: %w", err)
+	}
+
+	t.batchSize, _ = cfg.As[int](t.Arg("tabularium-batch-size"))
+	if t.batchSize < 0 {
+		return fmt.Errorf("tabularium-batch-size must not be negative, got %d", t.batchSize)
+	}
+
+	slog.Debug("creating tabularium output file", "filename", filename)
+	t.file, err = os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating tabularium output: %w", err)
+	}
+
+	t.w = bufio.NewWriter(t.file)
+	return nil
+}
+
+func (t *TabulariumOutputter) AcceptedTypes() []reflect.Type {
+	return []reflect.Type{reflect.TypeFor[Tabularizable]()}
+}
+
+func (t *TabulariumOutputter) TargetFiles() []string {
+	filename, err := cfg.As[string](t.Arg("tabulariumoutfile"))
+	if err != nil {
+		return nil
+	}
+	return []string{filename}
+}
+
+func (t *TabulariumOutputter) Output(val Tabularizable) error {
+	models, err := val.ToTabularium()
+	if err != nil {
+		return fmt.Errorf("error converting %T to tabularium: %w", val, err)
+	}
+
+	for _, model := range models {
+		if t.batchSize == 0 {
+			if err := t.writeLine(model); err != nil {
+				return err
+			}
+			continue
+		}
+
+		t.batch = append(t.batch, model)
+		if len(t.batch) >= t.batchSize {
+			if err := t.flushBatch(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *TabulariumOutputter) flushBatch() error {
+	if len(t.batch) == 0 {
+		return nil
+	}
+
+	err := t.writeLine(t.batch)
+	t.batch = nil
+	return err
+}
+
+func (t *TabulariumOutputter) writeLine(v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error marshaling tabularium model: %w", err)
+	}
+
+	if _, err := t.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing tabularium output: %w", err)
+	}
+	return nil
+}
+
+func (t *TabulariumOutputter) Complete() error {
+	if t.file == nil {
+		return nil
+	}
+
+	if err := t.flushBatch(); err != nil {
+		t.file.Close()
+		return err
+	}
+
+	if err := t.w.Flush(); err != nil {
+		t.file.Close()
+		return fmt.Errorf("error flushing tabularium output: %w", err)
+	}
+	return t.file.Close()
+}
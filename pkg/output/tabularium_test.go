@@ -0,0 +1,69 @@
+package output_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/output"
+	"github.com/praetorian-inc/tabularium/pkg/model/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tabulariumTestAsset struct {
+	DNS   string
+	Ports []int
+}
+
+type tabulariumTestModel struct {
+	Key string `json:"key"`
+}
+
+func (m tabulariumTestModel) GetKey() string      { return m.Key }
+func (m tabulariumTestModel) GetLabels() []string { return nil }
+func (m tabulariumTestModel) Valid() bool         { return true }
+
+func (a tabulariumTestAsset) ToTabularium() ([]model.GraphModel, error) {
+	models := []model.GraphModel{tabulariumTestModel{Key: "#asset#" + a.DNS}}
+	for _, port := range a.Ports {
+		models = append(models, tabulariumTestModel{Key: "#port#" + a.DNS + "#" + strconv.Itoa(port)})
+	}
+	return models, nil
+}
+
+func TestTabulariumOutputter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.jsonl")
+	outputter := output.NewTabulariumOutputter(cfg.WithArg("tabulariumoutfile", filename))
+	require.NoError(t, outputter.Initialize())
+
+	o := outputter.(*output.TabulariumOutputter)
+	require.NoError(t, o.Output(tabulariumTestAsset{DNS: "example.com", Ports: []int{1, 2}}))
+	require.NoError(t, outputter.Complete())
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, `{"key":"#asset#example.com"}
+{"key":"#port#example.com#1"}
+{"key":"#port#example.com#2"}
+`, string(content))
+}
+
+func TestTabulariumOutputter_Batched(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.jsonl")
+	outputter := output.NewTabulariumOutputter(cfg.WithArg("tabulariumoutfile", filename), cfg.WithArg("tabularium-batch-size", 2))
+	require.NoError(t, outputter.Initialize())
+
+	o := outputter.(*output.TabulariumOutputter)
+	require.NoError(t, o.Output(tabulariumTestAsset{DNS: "a.com", Ports: []int{1}}))
+	require.NoError(t, o.Output(tabulariumTestAsset{DNS: "b.com"}))
+	require.NoError(t, outputter.Complete())
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"key":"#asset#a.com"},{"key":"#port#a.com#1"}]
+[{"key":"#asset#b.com"}]
+`, string(content))
+}
@@ -0,0 +1,301 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+)
+
+// WebhookOutputter POSTs items to a URL as JSON arrays, so results of long-running chains can
+// be streamed to a collector as they are found.
+//
+// Items are sent in batches of webhook-batch-size. With webhook-flush-interval set, any partial
+// batch is also sent every that many seconds. Whatever remains is sent by Complete. Requests
+// answered with 429 or a 5xx status, or that fail to connect, are retried with exponential
+// back-off, honouring Retry-After when the server sends one; no wait between retries is longer
+// than webhook-timeout. A full batch is sent by the Output call that fills it, so Output may
+// block for the whole retry sequence, holding up the link that sent the item.
+//
+// A batch that still fails is put back to be sent with the next one, and the error is returned;
+// at most webhook-max-pending items are held this way, and the oldest are dropped past it. A
+// batch that can never be sent, because it cannot be marshaled or the server rejects it with
+// another 4xx status, is dropped and the error returned.
+type WebhookOutputter struct {
+	*chain.BaseOutputter
+	client     *http.Client
+	url        string
+	headers    http.Header
+	batchSize  int
+	maxRetries int
+	maxPending int
+	backoff    time.Duration
+	maxWait    time.Duration
+
+	sending sync.Mutex // held while sending, so batches are sent one at a time and in order
+	mu      sync.Mutex // guards batch and err; never held while sending
+	batch   []any
+	err     error // error from a send in the background, returned by the next Output or Complete
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func NewWebhookOutputter(configs ...cfg.Config) chain.Outputter {
+	w := &WebhookOutputter{}
+	w.BaseOutputter = chain.NewBaseOutputter(w, configs...)
+	return w
+}
+
+func (w *WebhookOutputter) Params() []cfg.Param {
+	return []cfg.Param{
+		cfg.NewParam[string]("webhook-url", "the URL to POST batches of items to").AsRequired(),
+		cfg.NewParam[[]string]("webhook-headers", `headers to send with each request, as "Name: value"`).AsSensitive(),
+		cfg.NewParam[int]("webhook-batch-size", "the number of items to send per request").WithDefault(100),
+		cfg.NewParam[int]("webhook-flush-interval", "seconds after which a partial batch is sent (0 sends only full batches until completion)").WithDefault(0),
+		cfg.NewParam[int]("webhook-max-retries", "retries for requests failing with 429, a 5xx status or a connection error").WithDefault(3),
+		cfg.NewParam[int]("webhook-max-pending", "the most unsent items to hold for resending after failed requests; the oldest are dropped past it").WithDefault(10000),
+		cfg.NewParam[int]("webhook-retry-backoff", "milliseconds to wait before the first retry, doubling for each retry after").WithDefault(500),
+		cfg.NewParam[int]("webhook-timeout", "seconds to wait for each request").WithDefault(30),
+	}
+}
+
+func (w *WebhookOutputter) Initialize() error {
+	var err error
+	w.url, err = cfg.As[string](w.Arg("webhook-url"))
+	if err != nil {
+		return fmt.Errorf("error getting webhook-url: %w", err)
+	}
+	if w.url == "" {
+		return fmt.Errorf("webhook-url is empty")
+	}
+
+	w.headers = http.Header{}
+	headers, _ := cfg.As[[]string](w.Arg("webhook-headers"))
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return fmt.Errorf("invalid webhook header %q, expected \"Name: value\"", header)
+		}
+		w.headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	w.batchSize, _ = cfg.As[int](w.Arg("webhook-batch-size"))
+	if w.batchSize <= 0 {
+		w.batchSize = 1
+	}
+	w.maxRetries, _ = cfg.As[int](w.Arg("webhook-max-retries"))
+	w.maxPending, _ = cfg.As[int](w.Arg("webhook-max-pending"))
+	backoff, _ := cfg.As[int](w.Arg("webhook-retry-backoff"))
+	w.backoff = time.Duration(backoff) * time.Millisecond
+	timeout, _ := cfg.As[int](w.Arg("webhook-timeout"))
+	w.client = &http.Client{Timeout: time.Duration(timeout) * time.Second}
+	w.maxWait = w.client.Timeout
+
+	flushInterval, _ := cfg.As[int](w.Arg("webhook-flush-interval"))
+	if flushInterval > 0 {
+		w.stop = make(chan struct{})
+		w.done = make(chan struct{})
+		go w.flushPeriodically(time.Duration(flushInterval) * time.Second)
+	}
+	return nil
+}
+
+func (w *WebhookOutputter) Output(val any) error {
+	w.mu.Lock()
+	if w.err != nil {
+		err := w.err
+		w.err = nil
+		w.batch = append(w.batch, val)
+		w.mu.Unlock()
+		return err
+	}
+
+	w.batch = append(w.batch, val)
+	full := len(w.batch) >= w.batchSize
+	w.mu.Unlock()
+
+	if !full {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *WebhookOutputter) flushPeriodically(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.flush(); err != nil {
+				w.mu.Lock()
+				if w.err == nil {
+					w.err = err
+				}
+				w.mu.Unlock()
+			}
+		}
+	}
+}
+
+// flush sends the current batch. If sending fails but may succeed later, the batch is put back
+// in front of any items output meanwhile, keeping at most maxPending items; otherwise it is
+// dropped.
+func (w *WebhookOutputter) flush() error {
+	w.sending.Lock()
+	defer w.sending.Unlock()
+
+	w.mu.Lock()
+	batch := w.batch
+	w.batch = nil
+	w.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("dropped %d items: error marshaling webhook batch: %w", len(batch), err)
+	}
+
+	requeue, err := w.send(body, len(batch))
+	if err == nil {
+		return nil
+	}
+	if !requeue {
+		return fmt.Errorf("dropped %d items: %w", len(batch), err)
+	}
+
+	w.mu.Lock()
+	w.batch = append(batch, w.batch...)
+	dropped := max(len(w.batch)-max(w.maxPending, 0), 0)
+	w.batch = w.batch[dropped:]
+	w.mu.Unlock()
+
+	if dropped > 0 {
+		err = fmt.Errorf("%w; dropped %d items past webhook-max-pending", err, dropped)
+	}
+	return err
+}
+
+// send POSTs body, retrying as configured. On failure it also reports whether the batch may
+// still be sent later.
+func (w *WebhookOutputter) send(body []byte, items int) (bool, error) {
+	ctx := w.Context()
+	wait := w.backoff
+
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := w.post(ctx, body)
+		if err == nil {
+			slog.Debug("sent webhook batch", "url", w.url, "items", items)
+			return false, nil
+		}
+
+		if retryAfter < 0 || attempt >= w.maxRetries {
+			return retryAfter >= 0 || ctx.Err() != nil, fmt.Errorf("error sending %d items to webhook: %w", items, err)
+		}
+
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		if w.maxWait > 0 {
+			wait = min(wait, w.maxWait)
+		}
+		slog.Debug("retrying webhook batch", "url", w.url, "attempt", attempt+1, "wait", wait, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return true, fmt.Errorf("error sending %d items to webhook: %w", items, ctx.Err())
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// post sends one request. On failure it returns how long to wait before retrying: 0 to use
+// the back-off, or a negative duration if the request should not be retried.
+func (w *WebhookOutputter) post(ctx context.Context, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+
+	for name, values := range w.headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return 0, nil
+	}
+
+	err = fmt.Errorf("webhook responded with %s", resp.Status)
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return -1, err
+	}
+
+	return parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()), err
+}
+
+// parseRetryAfter returns the wait a Retry-After header asks for, given as seconds or as an
+// HTTP date, or 0 if there is none.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
+
+func (w *WebhookOutputter) Complete() error {
+	if w.stop != nil {
+		close(w.stop)
+		<-w.done
+		w.stop = nil
+	}
+
+	w.mu.Lock()
+	err := w.err
+	w.err = nil
+	w.mu.Unlock()
+
+	if flushErr := w.flush(); flushErr != nil {
+		w.mu.Lock()
+		unsent := len(w.batch)
+		w.batch = nil
+		w.mu.Unlock()
+		if unsent > 0 {
+			flushErr = fmt.Errorf("%d items were not sent: %w", unsent, flushErr)
+		}
+		err = errors.Join(err, flushErr)
+	}
+	return err
+}
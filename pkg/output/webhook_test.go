@@ -0,0 +1,276 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+	"github.com/praetorian-inc/janus-framework/pkg/output"
+	"github.com/praetorian-inc/janus-framework/pkg/testutils/mocks/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type webhookCollector struct {
+	mu       sync.Mutex
+	batches  [][]any
+	headers  []http.Header
+	statuses []int  // statuses to answer with before accepting requests
+	retry    string // Retry-After header sent with the statuses
+}
+
+func (c *webhookCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.headers = append(c.headers, r.Header.Clone())
+	if len(c.statuses) > 0 {
+		if c.retry != "" {
+			w.Header().Set("Retry-After", c.retry)
+		}
+		w.WriteHeader(c.statuses[0])
+		c.statuses = c.statuses[1:]
+		return
+	}
+
+	var batch []any
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.batches = append(c.batches, batch)
+}
+
+func (c *webhookCollector) received() [][]any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.batches
+}
+
+func newWebhookOutputter(t *testing.T, url string, configs ...cfg.Config) *output.WebhookOutputter {
+	configs = append([]cfg.Config{cfg.WithArg("webhook-url", url), cfg.WithArg("webhook-retry-backoff", 1)}, configs...)
+	outputter := output.NewWebhookOutputter(configs...)
+	require.NoError(t, outputter.Initialize())
+	return outputter.(*output.WebhookOutputter)
+}
+
+func TestWebhookOutputter_Batches(t *testing.T) {
+	collector := &webhookCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	o := newWebhookOutputter(t, server.URL,
+		cfg.WithArg("webhook-batch-size", 2),
+		cfg.WithArg("webhook-headers", []string{"Authorization: Bearer secret"}),
+	)
+
+	for _, item := range []string{"a", "b", "c"} {
+		require.NoError(t, o.Output(item))
+	}
+	assert.Equal(t, [][]any{{"a", "b"}}, collector.received())
+
+	require.NoError(t, o.Complete())
+	assert.Equal(t, [][]any{{"a", "b"}, {"c"}}, collector.received())
+	assert.Equal(t, "Bearer secret", collector.headers[0].Get("Authorization"))
+	assert.Equal(t, "application/json", collector.headers[0].Get("Content-Type"))
+}
+
+func TestWebhookOutputter_Retries(t *testing.T) {
+	collector := &webhookCollector{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	o := newWebhookOutputter(t, server.URL)
+	require.NoError(t, o.Output(map[string]any{"host": "example.com"}))
+	require.NoError(t, o.Complete())
+
+	assert.Equal(t, [][]any{{map[string]any{"host": "example.com"}}}, collector.received())
+	assert.Len(t, collector.headers, 3)
+}
+
+func TestWebhookOutputter_RetryAfterDateIsCapped(t *testing.T) {
+	collector := &webhookCollector{
+		statuses: []int{http.StatusServiceUnavailable},
+		retry:    time.Now().Add(3 * time.Second).UTC().Format(http.TimeFormat),
+	}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	o := newWebhookOutputter(t, server.URL, cfg.WithArg("webhook-timeout", 1))
+	require.NoError(t, o.Output("a"))
+
+	started := time.Now()
+	require.NoError(t, o.Complete())
+	elapsed := time.Since(started)
+
+	assert.Equal(t, [][]any{{"a"}}, collector.received())
+	assert.GreaterOrEqual(t, elapsed, 900*time.Millisecond, "a Retry-After date should be honoured")
+	assert.Less(t, elapsed, 1900*time.Millisecond, "the wait should be capped at webhook-timeout")
+}
+
+func TestWebhookOutputter_GivesUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests int
+	}{
+		{"retries exhausted", []int{500, 500, 500}, 3},
+		{"client error not retried", []int{http.StatusBadRequest}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := &webhookCollector{statuses: tt.statuses}
+			server := httptest.NewServer(collector)
+			defer server.Close()
+
+			o := newWebhookOutputter(t, server.URL, cfg.WithArg("webhook-max-retries", 2))
+			require.NoError(t, o.Output("item"))
+
+			assert.ErrorContains(t, o.Complete(), "error sending 1 items to webhook")
+			assert.Empty(t, collector.received())
+			assert.Len(t, collector.headers, tt.requests)
+		})
+	}
+}
+
+func TestWebhookOutputter_FlushInterval(t *testing.T) {
+	collector := &webhookCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	o := newWebhookOutputter(t, server.URL, cfg.WithArg("webhook-flush-interval", 1))
+	require.NoError(t, o.Output("item"))
+
+	assert.Eventually(t, func() bool {
+		return len(collector.received()) == 1
+	}, 3*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, o.Complete())
+	assert.Equal(t, [][]any{{"item"}}, collector.received())
+}
+
+func TestWebhookOutputter_RequeuesFailedBatch(t *testing.T) {
+	collector := &webhookCollector{statuses: []int{500}}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	o := newWebhookOutputter(t, server.URL,
+		cfg.WithArg("webhook-batch-size", 2),
+		cfg.WithArg("webhook-max-retries", 0),
+	)
+
+	require.NoError(t, o.Output("a"))
+	assert.ErrorContains(t, o.Output("b"), "error sending 2 items to webhook")
+	require.NoError(t, o.Output("c"))
+	require.NoError(t, o.Complete())
+
+	assert.Equal(t, [][]any{{"a", "b", "c"}}, collector.received(), "a failed batch should be sent with the next one")
+}
+
+func TestWebhookOutputter_OutputDuringBackoff(t *testing.T) {
+	collector := &webhookCollector{statuses: []int{http.StatusServiceUnavailable}}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	o := newWebhookOutputter(t, server.URL,
+		cfg.WithArg("webhook-batch-size", 10),
+		cfg.WithArg("webhook-retry-backoff", 500),
+		cfg.WithArg("webhook-flush-interval", 1),
+	)
+	require.NoError(t, o.Output("a"))
+
+	assert.Eventually(t, func() bool {
+		collector.mu.Lock()
+		defer collector.mu.Unlock()
+		return len(collector.headers) == 1
+	}, 3*time.Second, 10*time.Millisecond, "the periodic flush should have started backing off")
+
+	started := time.Now()
+	require.NoError(t, o.Output("b"))
+	assert.Less(t, time.Since(started), 250*time.Millisecond, "Output should not wait for a back-off")
+
+	require.NoError(t, o.Complete())
+	assert.Equal(t, [][]any{{"a"}, {"b"}}, collector.received())
+}
+
+func TestWebhookOutputter_DropsRejectedBatch(t *testing.T) {
+	collector := &webhookCollector{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	o := newWebhookOutputter(t, server.URL, cfg.WithArg("webhook-batch-size", 2))
+
+	require.NoError(t, o.Output("a"))
+	assert.ErrorContains(t, o.Output("b"), "dropped 2 items")
+	require.NoError(t, o.Output("c"))
+	require.NoError(t, o.Complete())
+
+	assert.Equal(t, [][]any{{"c"}}, collector.received(), "a rejected batch should not be resent")
+}
+
+func TestWebhookOutputter_DropsUnmarshalableItem(t *testing.T) {
+	collector := &webhookCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	o := newWebhookOutputter(t, server.URL)
+
+	require.NoError(t, o.Output(make(chan int)))
+	assert.ErrorContains(t, o.Complete(), "error marshaling webhook batch")
+	assert.Empty(t, collector.headers)
+
+	require.NoError(t, o.Output("b"))
+	require.NoError(t, o.Complete())
+	assert.Equal(t, [][]any{{"b"}}, collector.received())
+}
+
+func TestWebhookOutputter_MaxPending(t *testing.T) {
+	collector := &webhookCollector{statuses: []int{500, 500}}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	o := newWebhookOutputter(t, server.URL,
+		cfg.WithArg("webhook-batch-size", 2),
+		cfg.WithArg("webhook-max-retries", 0),
+		cfg.WithArg("webhook-max-pending", 2),
+	)
+
+	require.NoError(t, o.Output("a"))
+	assert.ErrorContains(t, o.Output("b"), "error sending 2 items to webhook")
+	assert.ErrorContains(t, o.Output("c"), "dropped 1 items past webhook-max-pending")
+	require.NoError(t, o.Output("d"))
+	require.NoError(t, o.Complete())
+
+	assert.Equal(t, [][]any{{"b", "c", "d"}}, collector.received(), "the oldest items should be dropped")
+}
+
+func TestWebhookOutputter_PlanRedactsHeaders(t *testing.T) {
+	planWriter := &bytes.Buffer{}
+
+	module := chain.NewModule(
+		cfg.NewMetadata(
+			"webhook-plan-test",
+			"webhook plan test",
+		),
+	).WithLinks(
+		basics.NewStrLink,
+	).WithOutputters(
+		output.NewWebhookOutputter,
+	).WithAutoRun().WithDryRun(planWriter)
+
+	err := module.Run(
+		cfg.WithArg("webhook-url", "http://collector.invalid"),
+		cfg.WithArg("webhook-headers", []string{"Authorization: Bearer hunter2"}),
+	)
+	require.NoError(t, err)
+
+	assert.NotContains(t, planWriter.String(), "hunter2")
+	assert.Contains(t, planWriter.String(), "webhook-headers ([]string) = "+cfg.RedactedValue)
+}
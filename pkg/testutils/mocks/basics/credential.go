@@ -0,0 +1,51 @@
+package basics
+
+import (
+	"fmt"
+
+	"github.com/praetorian-inc/tabularium/pkg/model/model"
+
+	"github.com/praetorian-inc/janus-framework/pkg/chain"
+	"github.com/praetorian-inc/janus-framework/pkg/chain/cfg"
+)
+
+const MockCredentialType = model.CredentialType("mock")
+
+type MockCredential struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// CredentialLink requires a MockCredential and sends the credential's username for each input.
+type CredentialLink struct {
+	*chain.Base
+	credential MockCredential
+}
+
+func NewCredentialLink(configs ...cfg.Config) chain.Link {
+	cl := &CredentialLink{}
+	cl.Base = chain.NewBase(cl, configs...)
+	return cl
+}
+
+func (cl *CredentialLink) CredentialType() model.CredentialType {
+	return MockCredentialType
+}
+
+func (cl *CredentialLink) Initialize() error {
+	credential, err := chain.CredentialAs[MockCredential](cl)
+	if err != nil {
+		return err
+	}
+
+	if credential.Username == "" {
+		return fmt.Errorf("credential has no username")
+	}
+
+	cl.credential = credential
+	return nil
+}
+
+func (cl *CredentialLink) Process(input string) error {
+	return cl.Send(cl.credential.Username)
+}
@@ -29,7 +29,11 @@ func (l *DelayLink) Process(v any) error {
 		return err
 	}
 
-	time.Sleep(time.Duration(duration) * time.Second)
+	select {
+	case <-l.Context().Done():
+		return l.Context().Err()
+	case <-time.After(time.Duration(duration) * time.Second):
+	}
 	l.Send(v)
 	return nil
 }
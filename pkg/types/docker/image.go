@@ -12,9 +12,15 @@ import (
 	"os"
 
 	"github.com/docker/docker/api/types/registry"
+	"github.com/praetorian-inc/tabularium/pkg/model/model"
+
 	"github.com/praetorian-inc/janus-framework/pkg/types"
 )
 
+// RegistryCredentialType is the CredentialType of Docker registry credentials. Their values
+// decode into a registry.AuthConfig, such as "username", "password" and "serveraddress".
+const RegistryCredentialType = model.CredentialType("docker")
+
 type DockerManifest struct {
 	Config   string   `json:"Config"`
 	RepoTags []string `json:"RepoTags"`
@@ -28,6 +34,18 @@ type DockerImage struct {
 	Manifest   *RegistryManifestV2
 }
 
+// DefaultRegistryHost is the registry of images named without one.
+const DefaultRegistryHost = "docker.io"
+
+// RegistryHost returns the host of the registry named in the image reference, e.g. "ghcr.io"
+// for "ghcr.io/org/app:1.0", or DefaultRegistryHost if the reference doesn't name one.
+func (i *DockerImage) RegistryHost() string {
+	if matches := imageRegex.FindStringSubmatch(i.Image); matches != nil && matches[1] != "" {
+		return matches[1]
+	}
+	return DefaultRegistryHost
+}
+
 type DockerLayer struct {
 	*DockerImage
 	Digest string
@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -10,6 +11,8 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type RegistryAuthResponse struct {
@@ -41,10 +44,14 @@ type RegistryManifestList struct {
 	} `json:"manifests"`
 }
 
+// registryHTTPClient traces registry requests as children of the span in their context.
+var registryHTTPClient = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
 // DockerRegistryClient provides shared functionality for interacting with Docker registries
 type DockerRegistryClient struct {
 	token       string
 	dockerImage *DockerImage
+	ctx         context.Context
 }
 
 func NewDockerRegistryClient(dockerImage *DockerImage) *DockerRegistryClient {
@@ -53,6 +60,19 @@ func NewDockerRegistryClient(dockerImage *DockerImage) *DockerRegistryClient {
 	}
 }
 
+// WithContext sets the context of the client's requests, such as a link's TraceContext.
+func (drc *DockerRegistryClient) WithContext(ctx context.Context) *DockerRegistryClient {
+	drc.ctx = ctx
+	return drc
+}
+
+func (drc *DockerRegistryClient) context() context.Context {
+	if drc.ctx == nil {
+		return context.Background()
+	}
+	return drc.ctx
+}
+
 func (drc *DockerRegistryClient) RefreshToken() error {
 	imageName, tag := drc.ParseImageName(drc.dockerImage.Image)
 
@@ -69,12 +89,12 @@ func (drc *DockerRegistryClient) RefreshToken() error {
 	registryBase = drc.getRegistryBase()
 	url := fmt.Sprintf("%s/v2/%s/manifests/%s", registryBase, imageName, tag)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(drc.context(), "GET", url, nil)
 	if err != nil {
 		return err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := registryHTTPClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -240,7 +260,7 @@ func (drc *DockerRegistryClient) getTokenFromAuthEndpoint(authURL string, withAu
 		}
 	}
 
-	req, err := http.NewRequest("GET", authURL, nil)
+	req, err := http.NewRequestWithContext(drc.context(), "GET", authURL, nil)
 	if err != nil {
 		return "", err
 	}
@@ -252,7 +272,7 @@ func (drc *DockerRegistryClient) getTokenFromAuthEndpoint(authURL string, withAu
 		req.Header.Set("Authorization", "Basic "+encodedAuth)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := registryHTTPClient.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -273,7 +293,7 @@ func (drc *DockerRegistryClient) GetManifest(imageName, tag string) (*RegistryMa
 	registryBase := drc.getRegistryBase()
 	url := fmt.Sprintf("%s/v2/%s/manifests/%s", registryBase, imageName, tag)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(drc.context(), "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -320,7 +340,7 @@ func (drc *DockerRegistryClient) setAuthHeader(req *http.Request) {
 // If a 401 is received, it attempts to refresh the token and retry once.
 // If the retry also fails with 401, it returns an auth error.
 func (drc *DockerRegistryClient) doRequestWithRetry(req *http.Request) (*http.Response, error) {
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := registryHTTPClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -338,7 +358,7 @@ func (drc *DockerRegistryClient) doRequestWithRetry(req *http.Request) (*http.Re
 		drc.setAuthHeader(req)
 
 		// Retry the request
-		resp2, err := http.DefaultClient.Do(req)
+		resp2, err := registryHTTPClient.Do(req)
 		if err != nil {
 			return nil, err
 		}
@@ -409,7 +429,7 @@ func (drc *DockerRegistryClient) GetLayerData(image, digest string) ([]byte, err
 	registryBase := drc.getRegistryBase()
 	url := fmt.Sprintf("%s/v2/%s/blobs/%s", registryBase, image, digest)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(drc.context(), "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1,7 +1,14 @@
 package docker
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/docker/docker/api/types/registry"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestDockerRegistryClient_ParseImageName(t *testing.T) {
@@ -185,3 +192,39 @@ func TestDockerRegistryClient_ParseImageName_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestDockerRegistryClient_Tracing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("layer"))
+	}))
+	defer server.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "download")
+
+	dockerImage := &DockerImage{Image: "library/nginx", AuthConfig: registry.AuthConfig{ServerAddress: server.URL}}
+	registryClient := NewDockerRegistryClient(dockerImage).WithContext(ctx)
+
+	data, err := registryClient.GetLayerData("library/nginx", "sha256:abc")
+	parent.End()
+	if err != nil {
+		t.Fatalf("GetLayerData failed: %v", err)
+	}
+	if string(data) != "layer" {
+		t.Errorf("expected layer data %q, got %q", "layer", data)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	request := spans[0]
+	if request.Name != "HTTP GET" {
+		t.Errorf("expected registry request span %q, got %q", "HTTP GET", request.Name)
+	}
+	if request.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("registry request span should be a child of the context's span")
+	}
+}
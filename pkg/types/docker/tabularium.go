@@ -0,0 +1,18 @@
+package docker
+
+import (
+	"fmt"
+
+	"github.com/praetorian-inc/tabularium/pkg/model/model"
+)
+
+// ToTabularium maps the image onto a tabularium asset whose DNS is the image's registry and
+// whose name is the image reference, e.g. "docker.io" and "nginx:1.20".
+func (i *DockerImage) ToTabularium() ([]model.GraphModel, error) {
+	if i.Image == "" {
+		return nil, fmt.Errorf("docker image has no name")
+	}
+
+	asset := model.NewAsset(i.RegistryHost(), i.Image)
+	return []model.GraphModel{&asset}, nil
+}
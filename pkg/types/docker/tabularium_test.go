@@ -0,0 +1,37 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/praetorian-inc/tabularium/pkg/model/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerImage_ToTabularium(t *testing.T) {
+	tests := []struct {
+		image    string
+		registry string
+	}{
+		{"nginx:1.20", "docker.io"},
+		{"praetorian/nebula:v1.0", "docker.io"},
+		{"registry.example.com/team/app:2", "registry.example.com"},
+		{"localhost:5000/app", "localhost:5000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			models, err := (&DockerImage{Image: tt.image}).ToTabularium()
+			require.NoError(t, err)
+			require.Len(t, models, 1)
+
+			asset, ok := models[0].(*model.Asset)
+			require.True(t, ok)
+			assert.Equal(t, tt.registry, asset.DNS)
+			assert.Equal(t, tt.image, asset.Name)
+		})
+	}
+
+	_, err := (&DockerImage{}).ToTabularium()
+	assert.Error(t, err)
+}
@@ -31,6 +31,7 @@ type NPFinding struct {
 	RuleTextID string       `json:"rule_text_id"`
 	Provenance NPProvenance `json:"provenance"`
 	Snippet    NPSnippet    `json:"snippet"`
+	Location   NPLocation   `json:"location"`
 }
 
 type NPOutput struct {
@@ -62,6 +63,7 @@ func (n *NPOutput) ToNPFindings() []NPFinding {
 				RuleTextID: n.RuleTextID,
 				Provenance: provenance,
 				Snippet:    match.Snippet,
+				Location:   match.Location,
 			})
 		}
 	}
@@ -75,7 +77,8 @@ type NPMatch struct {
 		NPProvenance              // This is not a typo. Provenance data from NP can exist either directly in each "Provenance" item, or embedded in the "Payload" field.
 		Payload      NPProvenance `json:"payload,omitempty"`
 	} `json:"provenance"`
-	Snippet NPSnippet `json:"snippet"`
+	Snippet  NPSnippet  `json:"snippet"`
+	Location NPLocation `json:"location"`
 }
 
 func (n *NPMatch) ProvenanceOf(index int) (NPProvenance, error) {
@@ -101,6 +104,22 @@ type NPSnippet struct {
 	After    string `json:"after"`
 }
 
+// NPLocation is where a match is within its blob. Lines and columns start at 1; a zero line
+// means the location is unknown.
+type NPLocation struct {
+	SourceSpan NPSourceSpan `json:"source_span"`
+}
+
+type NPSourceSpan struct {
+	Start NPPosition `json:"start"`
+	End   NPPosition `json:"end"`
+}
+
+type NPPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
 type NPInput struct {
 	ContentBase64 string       `json:"content_base64,omitempty"`
 	Content       string       `json:"content,omitempty"`
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+
+	"github.com/praetorian-inc/tabularium/pkg/model/model"
+)
+
+// NoseyParkerSource is the source recorded on the tabularium risks of Nosey Parker findings.
+const NoseyParkerSource = "noseyparker"
+
+// ToTabularium maps the asset onto a tabularium asset, followed by a port for each of its
+// ports. The asset's DNS is its domain, IP or CIDR, in that order, and its name is its IP if it
+// has one.
+func (sa ScannableAsset) ToTabularium() ([]model.GraphModel, error) {
+	dns := cmp.Or(sa.Domain, sa.IP, sa.CIDR, sa.Target)
+	if dns == "" {
+		return nil, fmt.Errorf("scannable asset has no target")
+	}
+
+	asset := model.NewAsset(dns, cmp.Or(sa.IP, dns))
+	models := []model.GraphModel{&asset}
+	for _, p := range sa.Ports {
+		port, err := p.ToTabulariumPort(&asset)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, &port)
+	}
+	return models, nil
+}
+
+// ToTabulariumPort maps the port onto a tabularium port of parent. A port is only mapped along
+// with its asset, so Port doesn't implement ToTabularium itself.
+func (p Port) ToTabulariumPort(parent *model.Asset) (model.Port, error) {
+	number, err := strconv.Atoi(p.Port)
+	if err != nil || number < 1 || number > 65535 {
+		return model.Port{}, fmt.Errorf("invalid port %q", p.Port)
+	}
+
+	protocol := cmp.Or(p.Transport, TransportTCP)
+	return model.NewPort(string(protocol), number, parent), nil
+}
+
+// ToTabularium maps the finding onto a tabularium risk named after its rule, on an asset for
+// the repository or cloud resource it was found in. The matched secret is not included.
+func (f NPFinding) ToTabularium() ([]model.GraphModel, error) {
+	location := cmp.Or(f.Provenance.RepoPath, f.Provenance.ResourceID)
+	if location == "" {
+		return nil, fmt.Errorf("nosey parker finding %s has no repository or resource", f.FindingID)
+	}
+	if f.RuleTextID == "" {
+		return nil, fmt.Errorf("nosey parker finding %s has no rule", f.FindingID)
+	}
+
+	asset := model.NewAsset(location, location)
+	risk := model.NewRisk(&asset, f.RuleTextID, model.TriageHigh)
+	risk.Source = NoseyParkerSource
+	risk.Comment = f.RuleName
+	return []model.GraphModel{&asset, &risk}, nil
+}
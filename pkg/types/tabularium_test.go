@@ -0,0 +1,86 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/praetorian-inc/tabularium/pkg/model/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScannableAsset_ToTabularium(t *testing.T) {
+	sa := NewScannableAsset("example.com")
+	sa.IP = "192.168.1.1"
+	sa.AddPort(TransportTCP, "443")
+	sa.AddPort(TransportUDP, "53")
+
+	models, err := sa.ToTabularium()
+	require.NoError(t, err)
+	require.Len(t, models, 3)
+
+	asset, ok := models[0].(*model.Asset)
+	require.True(t, ok)
+	assert.Equal(t, "example.com", asset.DNS)
+	assert.Equal(t, "192.168.1.1", asset.Name)
+
+	https, ok := models[1].(*model.Port)
+	require.True(t, ok)
+	assert.Equal(t, "tcp", https.Protocol)
+	assert.Equal(t, 443, https.Port)
+
+	dns, ok := models[2].(*model.Port)
+	require.True(t, ok)
+	assert.Equal(t, "udp", dns.Protocol)
+	assert.Equal(t, 53, dns.Port)
+}
+
+func TestScannableAsset_ToTabularium_CIDR(t *testing.T) {
+	models, err := NewScannableAsset("10.0.0.0/24").ToTabularium()
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+
+	asset := models[0].(*model.Asset)
+	assert.Equal(t, "10.0.0.0/24", asset.DNS)
+	assert.Equal(t, "10.0.0.0/24", asset.Name)
+}
+
+func TestPort_ToTabulariumPort(t *testing.T) {
+	parent := model.NewAsset("example.com", "example.com")
+
+	port, err := Port{Port: "8080"}.ToTabulariumPort(&parent)
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", port.Protocol, "ports without a transport should default to tcp")
+	assert.Equal(t, 8080, port.Port)
+
+	for _, invalid := range []string{"", "http", "0", "65536"} {
+		_, err := Port{Transport: TransportTCP, Port: invalid}.ToTabulariumPort(&parent)
+		assert.Error(t, err, "port %q", invalid)
+	}
+}
+
+func TestNPFinding_ToTabularium(t *testing.T) {
+	finding := NPFinding{
+		FindingID:  "abc123",
+		RuleName:   "AWS API Key",
+		RuleTextID: "np.aws.1",
+		Provenance: NPProvenance{Kind: "git_repo", RepoPath: "https://github.com/example/repo"},
+		Snippet:    NPSnippet{Matching: "AKIAEXAMPLE"},
+	}
+
+	models, err := finding.ToTabularium()
+	require.NoError(t, err)
+	require.Len(t, models, 2)
+
+	asset := models[0].(*model.Asset)
+	assert.Equal(t, "https://github.com/example/repo", asset.DNS)
+
+	risk := models[1].(*model.Risk)
+	assert.Equal(t, "np.aws.1", risk.Name)
+	assert.Equal(t, model.TriageHigh, risk.Status)
+	assert.Equal(t, NoseyParkerSource, risk.Source)
+	assert.Equal(t, "AWS API Key", risk.Comment)
+	assert.NotContains(t, risk.Comment, "AKIAEXAMPLE")
+
+	_, err = NPFinding{FindingID: "abc123", RuleTextID: "np.aws.1"}.ToTabularium()
+	assert.ErrorContains(t, err, "no repository or resource")
+}